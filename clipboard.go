@@ -0,0 +1,75 @@
+package termio
+
+import (
+	"errors"
+	"sync"
+)
+
+// ClipboardKind is the selection target of an OSC 52 request.
+type ClipboardKind uint8
+
+const (
+	ClipboardStandard  ClipboardKind = 'c' // The system clipboard
+	ClipboardPrimary   ClipboardKind = 'p' // The primary selection (X11)
+	ClipboardSelection ClipboardKind = 's' // The selection buffer
+)
+
+// Map the selection target of an OSC 52 request to a clipboard kind. The
+// targets we don't know (q, 0-7) fall back to the system clipboard.
+func clipboardKindFromOSC(kind uint8) ClipboardKind {
+	switch ClipboardKind(kind) {
+	case ClipboardPrimary, ClipboardSelection:
+		return ClipboardKind(kind)
+	default:
+		return ClipboardStandard
+	}
+}
+
+// Clipboard is the clipboard programs read and write with OSC 52. This is
+// provided by the embedder, e.g. to bridge it to the system clipboard.
+//
+// Reading the clipboard lets any program running in the terminal, including
+// ones on remote hosts, see what the user copied. Implementations can deny
+// a read by returning an error, no reply is sent to the program then.
+type Clipboard interface {
+	// SetClipboard sets the contents of the clipboard.
+	SetClipboard(kind ClipboardKind, data string) error
+	// GetClipboard returns the contents of the clipboard.
+	GetClipboard(kind ClipboardKind) (string, error)
+}
+
+// ErrClipboardEmpty is returned by MemoryClipboard when nothing was set.
+var ErrClipboardEmpty = errors.New("clipboard is empty")
+
+// MemoryClipboard is an in-memory Clipboard, mainly useful for tests.
+type MemoryClipboard struct {
+	mu   sync.Mutex
+	data map[ClipboardKind]string
+}
+
+var _ Clipboard = (*MemoryClipboard)(nil)
+
+func NewMemoryClipboard() *MemoryClipboard {
+	return &MemoryClipboard{
+		data: make(map[ClipboardKind]string),
+	}
+}
+
+// SetClipboard implements Clipboard.
+func (c *MemoryClipboard) SetClipboard(kind ClipboardKind, data string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.data[kind] = data
+	return nil
+}
+
+// GetClipboard implements Clipboard.
+func (c *MemoryClipboard) GetClipboard(kind ClipboardKind) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	data, ok := c.data[kind]
+	if !ok {
+		return "", ErrClipboardEmpty
+	}
+	return data, nil
+}
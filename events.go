@@ -1,6 +1,9 @@
 package termio
 
 import (
+	"github.com/hnimtadd/termio/terminal"
+	"github.com/hnimtadd/termio/terminal/color"
+	"github.com/hnimtadd/termio/terminal/screen"
 	"github.com/hnimtadd/termio/terminal/sgr"
 )
 
@@ -22,6 +25,12 @@ const (
 	EventTypePrompt
 	EventTypeCommandStart
 	EventTypeCommandEnd
+	EventTypeTitle
+	EventTypePwd
+	EventTypeClipboard
+	EventTypeColor
+	EventTypeScreen
+	EventTypeCursorStyle
 )
 
 // Event represents a terminal event with its associated data
@@ -84,24 +93,77 @@ type ModeEvent struct {
 	ANSI     bool
 }
 
-// Prompt detection event data
+// Prompt detection event data, emitted when the shell finishes drawing the
+// prompt (OSC 133;B)
 type PromptEvent struct {
 	Content  string
 	Position struct{ X, Y int }
-	Type     string // "primary", "secondary", "continuation"
+	Type     string // "primary", "right", "secondary", "continuation"
+	Aid      string // Application id from the aid= option, if any
+	Click    string // Click-to-move support from the cl= option, if any
 }
 
-// Command execution event data
+// Command execution event data, emitted when the user submits the command
+// (OSC 133;C)
 type CommandStartEvent struct {
 	Content   string
 	Position  struct{ X, Y int }
 	Timestamp int64
+	Aid       string
 }
 
+// Command completion event data, emitted when the command finishes
+// (OSC 133;D)
 type CommandEndEvent struct {
 	Duration  int64 // milliseconds
-	ExitCode  int
+	ExitCode  int   // -1 if the shell did not report the exit code
 	Timestamp int64
+	Aid       string
+}
+
+// Window title change event data
+type TitleEvent struct {
+	Title    string
+	IconName string
+}
+
+// Working directory change event data, reported by the shell with OSC 7
+type PwdEvent struct {
+	Host  string
+	Path  string
+	Local bool // Whether the host is the machine termio is running on
+}
+
+// Clipboard access event data, emitted when a program sets or reads the
+// clipboard with OSC 52
+type ClipboardEvent struct {
+	Kind      ClipboardKind
+	Operation string // "set" or "get"
+	Data      string // The decoded data that was set or read
+}
+
+// Dynamic color change event data, emitted when a program changes or resets
+// a color with OSC 4, 10, 11, 12, 104 or 110-112
+type ColorEvent struct {
+	Target string    // "palette", "foreground", "background" or "cursor"
+	Index  int       // The palette index, -1 if not a single palette entry
+	RGB    color.RGB // The new color, unset when the whole palette was reset
+}
+
+// Screen switch event data, emitted when a program switches between the
+// primary and the alternate screen with mode 47, 1047 or 1049, or goes back
+// to the primary screen with a full reset (RIS)
+type ScreenEvent struct {
+	Screen terminal.ScreenType // The screen that is now active
+	Mode   int                 // The mode that caused the switch, 0 for RIS
+}
+
+// Cursor style event data, emitted when a program changes the shape of the
+// cursor (DECSCUSR), shows or hides it (mode 25) or resets it (DECSTR, RIS)
+type CursorStyleEvent struct {
+	Shape    screen.CursorShape
+	Blinking bool
+	Visible  bool
 }
 
 // EventCallback is a function that handles terminal events
@@ -156,6 +218,8 @@ func (em *EventManager) RegisterAllEvents(callback EventCallback) {
 		EventTypeCharacter, EventTypeCSI, EventTypeESC, EventTypeDCS, EventTypeOSC,
 		EventTypeSGR, EventTypeCarriageReturn, EventTypeLineFeed, EventTypeCursorMove,
 		EventTypeErase, EventTypeMode, EventTypePrompt, EventTypeCommandStart, EventTypeCommandEnd,
+		EventTypeTitle, EventTypePwd, EventTypeClipboard, EventTypeColor,
+		EventTypeScreen, EventTypeCursorStyle,
 	}
 	
 	for _, eventType := range eventTypes {
@@ -0,0 +1,118 @@
+package termio
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/hnimtadd/termio/logger"
+)
+
+// The maximum number of replies kept for the Responses channel while nobody
+// reads them, the oldest replies are dropped beyond this.
+const maxPendingResponses = 1024
+
+// responseQueue delivers the replies to the program running in the terminal
+// on a channel. Replies are queued in the order they are written so the
+// stream never blocks on a slow reader, a goroutine started by the first
+// call to Channel feeds them to the channel.
+type responseQueue struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	pending [][]byte
+	closed  bool
+
+	// The number of replies dropped because the queue was full, and
+	// whether replies are dropped since the channel was last read.
+	dropped  int
+	dropping bool
+
+	out   chan []byte
+	done  chan struct{}
+	start sync.Once
+
+	logger logger.Logger
+}
+
+func newResponseQueue(logger logger.Logger) *responseQueue {
+	q := &responseQueue{
+		out:    make(chan []byte),
+		done:   make(chan struct{}),
+		logger: logger,
+	}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+// Channel returns the channel the replies are delivered on, starting the
+// delivery on the first call.
+func (q *responseQueue) Channel() <-chan []byte {
+	q.start.Do(func() { go q.run() })
+	return q.out
+}
+
+// Dropped returns the number of replies dropped because the queue was full.
+func (q *responseQueue) Dropped() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.dropped
+}
+
+// Write implements io.Writer. The data is copied so callers can reuse it.
+func (q *responseQueue) Write(data []byte) (int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return len(data), nil
+	}
+	if len(q.pending) == maxPendingResponses {
+		q.pending = q.pending[1:]
+		q.dropped++
+		// Only warn once until the channel is read again.
+		if !q.dropping && q.logger != nil {
+			q.logger.Warn("response queue full, dropping the oldest replies",
+				"max", maxPendingResponses, "dropped", q.dropped)
+		}
+		q.dropping = true
+	}
+	q.pending = append(q.pending, slices.Clone(data))
+	q.cond.Signal()
+	return len(data), nil
+}
+
+// Close stops delivering replies and closes the channel. Pending replies
+// are dropped.
+func (q *responseQueue) Close() {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return
+	}
+	q.closed = true
+	q.cond.Signal()
+	q.mu.Unlock()
+	close(q.done)
+}
+
+func (q *responseQueue) run() {
+	defer close(q.out)
+	for {
+		q.mu.Lock()
+		for len(q.pending) == 0 && !q.closed {
+			q.cond.Wait()
+		}
+		if q.closed {
+			q.mu.Unlock()
+			return
+		}
+		data := q.pending[0]
+		q.pending = q.pending[1:]
+		q.dropping = false
+		q.mu.Unlock()
+
+		select {
+		case q.out <- data:
+		case <-q.done:
+			return
+		}
+	}
+}
@@ -1,13 +1,28 @@
 package termio
 
 import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/hnimtadd/termio/logger"
 	"github.com/hnimtadd/termio/terminal"
+	"github.com/hnimtadd/termio/terminal/charsets"
 	"github.com/hnimtadd/termio/terminal/color"
 	"github.com/hnimtadd/termio/terminal/core"
 	"github.com/hnimtadd/termio/terminal/handler"
+	"github.com/hnimtadd/termio/terminal/kitty"
+	"github.com/hnimtadd/termio/terminal/page"
+	"github.com/hnimtadd/termio/terminal/pagelist"
 	"github.com/hnimtadd/termio/terminal/sequences/csi"
 	"github.com/hnimtadd/termio/terminal/sequences/dcs"
+	"github.com/hnimtadd/termio/terminal/sequences/osc"
 	"github.com/hnimtadd/termio/terminal/sgr"
 )
 
@@ -25,11 +40,18 @@ type StreamHandler struct {
 	defaultForegroundColor color.RGB
 	defaultBackgroundColor color.RGB
 
+	// The default cursor color, nil means the cursor follows the
+	// foreground color.
+	defaultCursorColor *color.RGB
+
 	// The foreground and background color as set by an OSC 10 or OSC 11
 	// sequence. If unset the respective color is the default value.
 	foregroundColor color.RGB
 	backgroundColor color.RGB
 
+	// The cursor color as set by an OSC 12 sequence, nil if unset.
+	cursorColor *color.RGB
+
 	// -----------------------------------------------------------------------
 	// Internal state
 
@@ -38,6 +60,24 @@ type StreamHandler struct {
 	// as XGETTCAP.
 	dcs dcs.Handler
 
+	// The last working directory reported by OSC 7, used to only emit
+	// events when the directory changes.
+	lastPwd PwdEvent
+
+	// Semantic prompt (OSC 133) state. The pins are tracked so they stay
+	// valid when the screen scrolls while the prompt or command is written.
+	prompt       osc.PromptStart
+	promptStart  *pagelist.Pin
+	inputStart   *pagelist.Pin
+	commandStart time.Time
+	commandAid   string
+
+	// Where replies to the program running in the terminal are written.
+	responseWriter io.Writer
+
+	// The clipboard for OSC 52, nil if not supported.
+	clipboard Clipboard
+
 	// Event manager for callbacks
 	eventManager *EventManager
 
@@ -100,9 +140,49 @@ func (s *StreamHandler) EraseInLine(mode csi.ELMode) {
 	s.terminal.EraseInLine(mode)
 }
 
+// EraseChars implements streamHandler.
+func (s *StreamHandler) EraseChars(repeated uint16) {
+	s.terminal.EraseChars(repeated)
+}
+
+// ScrollUp implements streamHandler.
+func (s *StreamHandler) ScrollUp(repeated uint16) {
+	s.terminal.ScrollUp(repeated)
+}
+
+// ScrollDown implements streamHandler.
+func (s *StreamHandler) ScrollDown(repeated uint16) {
+	s.terminal.ScrollDown(repeated)
+}
+
 // FullReset implements streamHandler.
 func (s *StreamHandler) FullReset() {
+	active := s.terminal.ActiveScreen()
+	cursor := s.cursorStyle()
 	s.terminal.FullReset()
+	s.foregroundColor = s.defaultForegroundColor
+	s.backgroundColor = s.defaultBackgroundColor
+	s.cursorColor = nil
+
+	// The reset goes back to the primary screen.
+	if now := s.terminal.ActiveScreen(); now != active {
+		s.eventManager.EmitEvent(&Event{
+			Type: EventTypeScreen,
+			Data: ScreenEvent{Screen: now},
+		})
+	}
+	if s.cursorStyle() != cursor {
+		s.emitCursorStyle()
+	}
+}
+
+// SoftReset implements streamHandler.
+func (s *StreamHandler) SoftReset() {
+	cursor := s.cursorStyle()
+	s.terminal.SoftReset()
+	if s.cursorStyle() != cursor {
+		s.emitCursorStyle()
+	}
 }
 
 // Index implements streamHandler.
@@ -157,6 +237,18 @@ func (s *StreamHandler) Print(c uint32) {
 	s.terminal.Print(c)
 }
 
+// PrintRepeat implements streamHandler.
+func (s *StreamHandler) PrintRepeat(repeated uint16) {
+	// The characters are printed one by one so each of them is reported.
+	c, ok := s.terminal.PreviousChar()
+	if !ok {
+		return
+	}
+	for range max(repeated, 1) {
+		s.Print(c)
+	}
+}
+
 // ReverseIndex implements streamHandler.
 func (s *StreamHandler) ReverseIndex() {
 	s.terminal.ReverseIndex()
@@ -164,8 +256,12 @@ func (s *StreamHandler) ReverseIndex() {
 
 // SetCursorCol implements streamHandler.
 func (s *StreamHandler) SetCursorCol(col uint16) {
-	// plus one because the cursor is 0-indexed and the display is 1-indexed
-	s.terminal.SetCursorPosition(uint16(s.terminal.Screen.Cursor.Y+1), col)
+	s.terminal.SetCursorCol(col)
+}
+
+// SetCursorColRelative implements streamHandler.
+func (s *StreamHandler) SetCursorColRelative(offset uint16) {
+	s.terminal.SetCursorColRelative(offset)
 }
 
 // SetCursorDown implements streamHandler.
@@ -202,8 +298,12 @@ func (s *StreamHandler) SetCursorRight(offset uint16) {
 
 // SetCursorRow implements streamHandler.
 func (s *StreamHandler) SetCursorRow(row uint16) {
-	// plus one because the cursor is 0-indexed and the display is 1-indexed
-	s.terminal.SetCursorPosition(row, uint16(s.terminal.Screen.Cursor.X+1))
+	s.terminal.SetCursorRow(row)
+}
+
+// SetCursorRowRelative implements streamHandler.
+func (s *StreamHandler) SetCursorRowRelative(offset uint16) {
+	s.terminal.SetCursorRowRelative(offset)
 }
 
 // SetCursorTabLeft implements streamHandler.
@@ -233,18 +333,7 @@ func (s *StreamHandler) SetGraphicsRendition(attr *sgr.Attribute) {
 	
 	switch attr.Type {
 	case sgr.AttributeTypeUnknown:
-		// Only log warning for truly unknown attributes, not standard ANSI colors
-		if len(attr.Unknown.Partial) == 1 {
-			code := attr.Unknown.Partial[0]
-			// Standard ANSI colors (30-37, 40-47, 90-97, 100-107) - don't warn
-			if !((code >= 30 && code <= 37) || (code >= 40 && code <= 47) || 
-				 (code >= 90 && code <= 97) || (code >= 100 && code <= 107)) {
-				s.logger.Warn("Unknown SGR attribute", "attribute", attr)
-			}
-		} else {
-			// Complex sequences - still log these
-			s.logger.Warn("Unknown SGR attribute", "attribute", attr)
-		}
+		s.logger.Warn("Unknown SGR attribute", "attribute", attr)
 	default:
 		s.terminal.SetGraphicsRendition(attr)
 	}
@@ -255,6 +344,16 @@ func (s *StreamHandler) TabSet() {
 	s.terminal.TabSet()
 }
 
+// TabClear implements streamHandler.
+func (s *StreamHandler) TabClear(mode csi.TBCMode) {
+	s.terminal.TabClear(mode)
+}
+
+// TabReset implements streamHandler.
+func (s *StreamHandler) TabReset() {
+	s.terminal.TabReset()
+}
+
 // SetMode implements streamHandler.
 func (s *StreamHandler) SetMode(mode core.Mode, enabled bool) {
 	// Handle special modes that should be ignored
@@ -262,6 +361,10 @@ func (s *StreamHandler) SetMode(mode core.Mode, enabled bool) {
 		// Mode 0 is defined as error/ignored mode - silently ignore it
 		return
 	}
+	if mode.Permanent {
+		// The mode can't be changed, there is nothing to report.
+		return
+	}
 	
 	// Emit mode change event
 	s.eventManager.EmitEvent(&Event{
@@ -274,7 +377,20 @@ func (s *StreamHandler) SetMode(mode core.Mode, enabled bool) {
 		},
 	})
 	
-	s.terminal.Modes.Set(mode, enabled)
+	active := s.terminal.ActiveScreen()
+	s.terminal.SetMode(mode, enabled)
+	if mode == core.ModeCursorVisible {
+		s.emitCursorStyle()
+	}
+	if now := s.terminal.ActiveScreen(); now != active {
+		s.eventManager.EmitEvent(&Event{
+			Type: EventTypeScreen,
+			Data: ScreenEvent{
+				Screen: now,
+				Mode:   mode.Value,
+			},
+		})
+	}
 	
 	// Log mode changes for debugging
 	if s.logger != nil {
@@ -286,6 +402,570 @@ func (s *StreamHandler) SetMode(mode core.Mode, enabled bool) {
 	}
 }
 
+// SetCursorStyle implements handler.CursorStyleHandler.
+func (s *StreamHandler) SetCursorStyle(style csi.CursorStyle) {
+	s.terminal.SetCursorStyle(style)
+	s.emitCursorStyle()
+}
+
+// emitCursorStyle reports how the cursor is drawn now.
+func (s *StreamHandler) emitCursorStyle() {
+	s.eventManager.EmitEvent(&Event{
+		Type: EventTypeCursorStyle,
+		Data: s.cursorStyle(),
+	})
+}
+
+// Return how the cursor is drawn now.
+func (s *StreamHandler) cursorStyle() CursorStyleEvent {
+	cursor := s.terminal.Screen.Cursor
+	return CursorStyleEvent{
+		Shape:    cursor.Shape,
+		Blinking: cursor.Blinking,
+		Visible:  cursor.Visible,
+	}
+}
+
+// SetModifyOtherKeys implements handler.KeyboardHandler.
+func (s *StreamHandler) SetModifyOtherKeys(level core.ModifyOtherKeys) {
+	s.terminal.Modes.SetModifyOtherKeys(level)
+}
+
+// PushKittyKeyboard implements handler.KeyboardHandler.
+func (s *StreamHandler) PushKittyKeyboard(flags kitty.KeyFlags) {
+	s.terminal.Screen.KittyKeyboard.Push(flags)
+}
+
+// PopKittyKeyboard implements handler.KeyboardHandler.
+func (s *StreamHandler) PopKittyKeyboard(n uint16) {
+	s.terminal.Screen.KittyKeyboard.Pop(int(n))
+}
+
+// SetKittyKeyboard implements handler.KeyboardHandler.
+func (s *StreamHandler) SetKittyKeyboard(mode kitty.KeySetMode, flags kitty.KeyFlags) {
+	s.terminal.Screen.KittyKeyboard.Set(mode, flags)
+}
+
+// QueryKittyKeyboard implements handler.KeyboardHandler.
+func (s *StreamHandler) QueryKittyKeyboard() {
+	flags := s.terminal.Screen.KittyKeyboard.Current()
+	s.respond(fmt.Appendf(nil, "\x1b[?%du", flags))
+}
+
+// SaveMode implements handler.VT100Handler.
+func (s *StreamHandler) SaveMode(mode core.Mode) {
+	s.terminal.Modes.Save(mode)
+}
+
+// RestoreMode implements handler.VT100Handler.
+func (s *StreamHandler) RestoreMode(mode core.Mode) {
+	// Go through SetMode so the mode events are emitted and the side
+	// effects of the mode, e.g. switching screens, apply.
+	if value, ok := s.terminal.Modes.Saved(mode); ok {
+		s.SetMode(mode, value)
+	}
+}
+
+// ChangeWindowTitle implements streamHandler.
+func (s *StreamHandler) ChangeWindowTitle(target osc.TitleTarget, title string) {
+	title0, iconName0 := s.terminal.GetTitle(), s.terminal.GetIconName()
+
+	if target == osc.TitleTargetBoth || target == osc.TitleTargetWindow {
+		s.terminal.SetTitle(title)
+	}
+	if target == osc.TitleTargetBoth || target == osc.TitleTargetIcon {
+		s.terminal.SetIconName(title)
+	}
+	s.emitTitleChanged(title0, iconName0)
+}
+
+// PushTitle implements streamHandler.
+func (s *StreamHandler) PushTitle(target osc.TitleTarget) {
+	s.terminal.PushTitle(target)
+}
+
+// PopTitle implements streamHandler.
+func (s *StreamHandler) PopTitle(target osc.TitleTarget) {
+	title0, iconName0 := s.terminal.GetTitle(), s.terminal.GetIconName()
+	s.terminal.PopTitle(target)
+	s.emitTitleChanged(title0, iconName0)
+}
+
+// emitTitleChanged emits a title event if either the window title or the
+// icon name differs from the given previous values.
+func (s *StreamHandler) emitTitleChanged(title0, iconName0 string) {
+	title, iconName := s.terminal.GetTitle(), s.terminal.GetIconName()
+	if title == title0 && iconName == iconName0 {
+		return
+	}
+	s.eventManager.EmitEvent(&Event{
+		Type: EventTypeTitle,
+		Data: TitleEvent{
+			Title:    title,
+			IconName: iconName,
+		},
+	})
+}
+
+// ReportPwd implements streamHandler.
+func (s *StreamHandler) ReportPwd(uri string) {
+	host, path, err := parsePwdURI(uri)
+	if err != nil {
+		s.logger.Warn("invalid OSC 7 pwd", "uri", uri, "error", err)
+		return
+	}
+
+	// The pwd is only meaningful for us when the shell runs on this machine,
+	// e.g. a shell inside ssh reports the path on the remote host.
+	local := isLocalHostname(host)
+	if local {
+		s.terminal.SetPwd(path)
+	}
+
+	event := PwdEvent{Host: host, Path: path, Local: local}
+	if event == s.lastPwd {
+		return
+	}
+	s.lastPwd = event
+	s.eventManager.EmitEvent(&Event{
+		Type: EventTypePwd,
+		Data: event,
+	})
+}
+
+// StartHyperlink implements streamHandler.
+func (s *StreamHandler) StartHyperlink(uri, id string) {
+	s.terminal.StartHyperlink(uri, id)
+}
+
+// EndHyperlink implements streamHandler.
+func (s *StreamHandler) EndHyperlink() {
+	s.terminal.EndHyperlink()
+}
+
+// ClipboardContents implements streamHandler.
+func (s *StreamHandler) ClipboardContents(
+	kind uint8,
+	data string,
+	terminator osc.Terminator,
+) {
+	if s.clipboard == nil {
+		s.logger.Warn("OSC 52 ignored, no clipboard configured")
+		return
+	}
+	clipboardKind := clipboardKindFromOSC(kind)
+
+	if data == "?" {
+		contents, err := s.clipboard.GetClipboard(clipboardKind)
+		if err != nil {
+			s.logger.Warn("failed to read clipboard", "kind", kind, "error", err)
+			return
+		}
+		s.respond(fmt.Appendf(nil, "\x1b]52;%c;%s%s",
+			clipboardKind,
+			base64.StdEncoding.EncodeToString([]byte(contents)),
+			terminator,
+		))
+		s.eventManager.EmitEvent(&Event{
+			Type: EventTypeClipboard,
+			Data: ClipboardEvent{
+				Kind:      clipboardKind,
+				Operation: "get",
+				Data:      contents,
+			},
+		})
+		return
+	}
+
+	// Some programs don't pad their payload, accept both.
+	decoded, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		decoded, err = base64.RawStdEncoding.DecodeString(data)
+	}
+	if err != nil {
+		s.logger.Warn("invalid OSC 52 payload", "error", err)
+		return
+	}
+	if err := s.clipboard.SetClipboard(clipboardKind, string(decoded)); err != nil {
+		s.logger.Warn("failed to set clipboard", "kind", kind, "error", err)
+		return
+	}
+	s.eventManager.EmitEvent(&Event{
+		Type: EventTypeClipboard,
+		Data: ClipboardEvent{
+			Kind:      clipboardKind,
+			Operation: "set",
+			Data:      string(decoded),
+		},
+	})
+}
+
+// ColorOperation implements streamHandler.
+func (s *StreamHandler) ColorOperation(
+	ops []osc.ColorOperation,
+	terminator osc.Terminator,
+) {
+	for _, op := range ops {
+		switch op.Type {
+		case osc.ColorOperationSet:
+			rgb := op.RGB
+			s.setColor(op.Target, &rgb)
+
+		case osc.ColorOperationReset:
+			s.setColor(op.Target, nil)
+
+		case osc.ColorOperationResetPalette:
+			s.terminal.ResetPalette()
+			s.eventManager.EmitEvent(&Event{
+				Type: EventTypeColor,
+				Data: ColorEvent{Target: "palette", Index: -1},
+			})
+
+		case osc.ColorOperationQuery:
+			var code string
+			switch op.Target.Type {
+			case osc.ColorTargetPalette:
+				code = fmt.Sprintf("4;%d", op.Target.Index)
+			case osc.ColorTargetForeground:
+				code = "10"
+			case osc.ColorTargetBackground:
+				code = "11"
+			case osc.ColorTargetCursor:
+				code = "12"
+			}
+			s.respond(fmt.Appendf(nil, "\x1b]%s;%s%s",
+				code, s.color(op.Target).Spec(), terminator))
+		}
+	}
+}
+
+// Return the current color of the target.
+func (s *StreamHandler) color(target osc.ColorTarget) color.RGB {
+	switch target.Type {
+	case osc.ColorTargetPalette:
+		return s.terminal.PaletteColor(target.Index)
+	case osc.ColorTargetForeground:
+		return s.foregroundColor
+	case osc.ColorTargetBackground:
+		return s.backgroundColor
+	case osc.ColorTargetCursor:
+		if s.cursorColor != nil {
+			return *s.cursorColor
+		}
+		if s.defaultCursorColor != nil {
+			return *s.defaultCursorColor
+		}
+		return s.foregroundColor
+	default:
+		return color.RGB{}
+	}
+}
+
+// Change the color of the target, a nil color resets it to its default.
+func (s *StreamHandler) setColor(target osc.ColorTarget, rgb *color.RGB) {
+	event := ColorEvent{Index: -1}
+	switch target.Type {
+	case osc.ColorTargetPalette:
+		event.Target = "palette"
+		event.Index = int(target.Index)
+		if rgb != nil {
+			s.terminal.SetPaletteColor(target.Index, *rgb)
+		} else {
+			s.terminal.ResetPaletteColor(target.Index)
+		}
+	case osc.ColorTargetForeground:
+		event.Target = "foreground"
+		s.foregroundColor = s.defaultForegroundColor
+		if rgb != nil {
+			s.foregroundColor = *rgb
+		}
+	case osc.ColorTargetBackground:
+		event.Target = "background"
+		s.backgroundColor = s.defaultBackgroundColor
+		if rgb != nil {
+			s.backgroundColor = *rgb
+		}
+	case osc.ColorTargetCursor:
+		event.Target = "cursor"
+		s.cursorColor = rgb
+	}
+	event.RGB = s.color(target)
+	s.eventManager.EmitEvent(&Event{
+		Type: EventTypeColor,
+		Data: event,
+	})
+}
+
+// SetTopAndBottomMargin implements handler.MarginHandler.
+func (s *StreamHandler) SetTopAndBottomMargin(top, bottom uint16) {
+	s.terminal.SetTopAndBottomMargin(top, bottom)
+}
+
+// SetLeftAndRightMargin implements handler.MarginHandler.
+func (s *StreamHandler) SetLeftAndRightMargin(left, right uint16) {
+	s.terminal.SetLeftAndRightMargin(left, right)
+}
+
+// SetLeftAndRightMarginAmbiguous implements handler.MarginHandler.
+func (s *StreamHandler) SetLeftAndRightMarginAmbiguous() {
+	if s.terminal.Modes.Get(core.ModeEnableLeftAndRightMargin) {
+		s.terminal.SetLeftAndRightMargin(0, 0)
+		return
+	}
+	s.terminal.SaveCursor()
+}
+
+// SaveCursor implements handler.CursorStateHandler.
+func (s *StreamHandler) SaveCursor() {
+	s.terminal.SaveCursor()
+}
+
+// RestoreCursor implements handler.CursorStateHandler.
+func (s *StreamHandler) RestoreCursor() {
+	s.terminal.RestoreCursor()
+}
+
+// ConfigureCharset implements handler.CharsetHandler.
+func (s *StreamHandler) ConfigureCharset(slot charsets.Slot, set charsets.Charset) {
+	s.terminal.ConfigureCharset(slot, set)
+}
+
+// InvokeCharset implements handler.CharsetHandler.
+func (s *StreamHandler) InvokeCharset(active charsets.ActiveSlot, slot charsets.Slot, single bool) {
+	s.terminal.InvokeCharset(active, slot, single)
+}
+
+// DeviceAttributes implements handler.DeviceReportHandler.
+func (s *StreamHandler) DeviceAttributes(req csi.DARequest) {
+	identity := s.terminal.DeviceIdentity()
+	switch req {
+	case csi.DARequestPrimary:
+		attrs := make([]string, len(identity.Primary))
+		for i, attr := range identity.Primary {
+			attrs[i] = strconv.FormatUint(uint64(attr), 10)
+		}
+		s.respond(fmt.Appendf(nil, "\x1b[?%sc", strings.Join(attrs, ";")))
+	case csi.DARequestSecondary:
+		s.respond(fmt.Appendf(
+			nil,
+			"\x1b[>%d;%d;%dc",
+			identity.Type,
+			identity.Version,
+			identity.Cartridge,
+		))
+	case csi.DARequestTertiary:
+		s.respond(fmt.Appendf(nil, "\x1bP!|%08X\x1b\\", identity.UnitID))
+	default:
+		s.logger.Warn("unimplemented device attributes request", "request", req)
+	}
+}
+
+// DeviceStatusReport implements handler.DeviceReportHandler.
+func (s *StreamHandler) DeviceStatusReport(req csi.DSRRequest) {
+	switch req {
+	case csi.DSRRequestOperatingStatus:
+		// We are always ready.
+		s.respond([]byte("\x1b[0n"))
+	case csi.DSRRequestCursorPosition:
+		row, col := s.terminal.CursorReportPosition()
+		s.respond(fmt.Appendf(nil, "\x1b[%d;%dR", row, col))
+	case csi.DSRRequestExtendedCursorPosition:
+		// We only have a single page, DECXCPR always reports page 1.
+		row, col := s.terminal.CursorReportPosition()
+		s.respond(fmt.Appendf(nil, "\x1b[?%d;%d;1R", row, col))
+	default:
+		s.logger.Warn("unimplemented device status report", "request", req)
+	}
+}
+
+// TabStopReport implements handler.DeviceReportHandler.
+func (s *StreamHandler) TabStopReport() {
+	// The columns of the tab stops are 1-indexed and separated by '/'.
+	stops := s.terminal.Tabstops()
+	cols := make([]string, len(stops))
+	for i, col := range stops {
+		cols[i] = strconv.FormatUint(uint64(col)+1, 10)
+	}
+	s.respond(fmt.Appendf(nil, "\x1bP2$u%s\x1b\\", strings.Join(cols, "/")))
+}
+
+// RequestMode implements handler.DeviceReportHandler.
+func (s *StreamHandler) RequestMode(value int, ansi bool) {
+	report := core.ModeReportNotRecognized
+	if mode := core.ModeFromInt(value, ansi); mode != nil {
+		report = s.terminal.Modes.Report(*mode)
+	}
+	prefix := "?"
+	if ansi {
+		prefix = ""
+	}
+	s.respond(fmt.Appendf(nil, "\x1b[%s%d;%d$y", prefix, value, report))
+}
+
+// respond writes a reply to the program running in the terminal, e.g. the
+// answer to a query.
+func (s *StreamHandler) respond(data []byte) {
+	if s.responseWriter == nil {
+		return
+	}
+	if _, err := s.responseWriter.Write(data); err != nil {
+		s.logger.Warn("failed to write response", "error", err)
+	}
+}
+
+// parsePwdURI parses the file://host/path URI reported by OSC 7, the path
+// is percent-decoded.
+func parsePwdURI(uri string) (host, path string, err error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", "", err
+	}
+	if u.Scheme != "file" {
+		return "", "", fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+	if u.Path == "" {
+		return "", "", errors.New("empty path")
+	}
+	return u.Hostname(), u.Path, nil
+}
+
+// isLocalHostname reports whether the host reported by OSC 7 is the machine
+// we're running on.
+func isLocalHostname(host string) bool {
+	if host == "" || host == "localhost" {
+		return true
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(host, hostname)
+}
+
+// PromptStart implements streamHandler.
+func (s *StreamHandler) PromptStart(prompt osc.PromptStart) {
+	switch prompt.Kind {
+	case osc.PromptKindContinuation, osc.PromptKindSecondary:
+		s.terminal.MarkSemanticPrompt(page.SemanticPromptTypeContinuation)
+	default:
+		s.terminal.MarkSemanticPrompt(page.SemanticPromptTypePrompt)
+	}
+
+	// Continuation prompts don't have to repeat the aid of the command
+	// they are part of.
+	if prompt.Aid == "" && prompt.Kind != osc.PromptKindPrimary {
+		prompt.Aid = s.prompt.Aid
+	}
+	s.prompt = prompt
+	s.promptStart = s.retrackCursorPin(s.promptStart)
+}
+
+// PromptEnd implements streamHandler.
+func (s *StreamHandler) PromptEnd() {
+	s.terminal.MarkSemanticPrompt(page.SemanticPromptTypeInput)
+
+	var content string
+	if s.promptStart != nil {
+		content = s.terminal.StringFromPin(s.promptStart)
+	}
+	event := PromptEvent{
+		Content: content,
+		Type:    s.prompt.Kind.String(),
+		Aid:     s.prompt.Aid,
+		Click:   s.prompt.Click,
+	}
+	event.Position.X, event.Position.Y = s.cursorPosition()
+	s.eventManager.EmitEvent(&Event{
+		Type: EventTypePrompt,
+		Data: event,
+	})
+
+	// The input of a continuation prompt is part of the command that
+	// started at the primary prompt.
+	if s.inputStart == nil || s.prompt.Kind == osc.PromptKindPrimary {
+		s.inputStart = s.retrackCursorPin(s.inputStart)
+	}
+}
+
+// EndOfInput implements streamHandler.
+func (s *StreamHandler) EndOfInput() {
+	var content string
+	if s.inputStart != nil {
+		content = strings.TrimSpace(s.terminal.StringFromPin(s.inputStart))
+	}
+	s.terminal.MarkSemanticPrompt(page.SemanticPromptTypeOutput)
+
+	s.commandStart = time.Now()
+	s.commandAid = s.prompt.Aid
+	event := CommandStartEvent{
+		Content:   content,
+		Timestamp: s.commandStart.UnixMilli(),
+		Aid:       s.commandAid,
+	}
+	event.Position.X, event.Position.Y = s.cursorPosition()
+	s.eventManager.EmitEvent(&Event{
+		Type: EventTypeCommandStart,
+		Data: event,
+	})
+
+	s.untrackPromptPins()
+}
+
+// EndOfCommand implements streamHandler.
+func (s *StreamHandler) EndOfCommand(end osc.EndOfCommand) {
+	s.untrackPromptPins()
+
+	// D without a preceding C happens for an empty command line or when the
+	// shell reports the status of the previous prompt, there is no command
+	// to report in that case.
+	if s.commandStart.IsZero() {
+		return
+	}
+	now := time.Now()
+	event := CommandEndEvent{
+		Duration:  now.Sub(s.commandStart).Milliseconds(),
+		ExitCode:  -1,
+		Timestamp: now.UnixMilli(),
+		Aid:       s.commandAid,
+	}
+	if end.ExitCode != nil {
+		event.ExitCode = *end.ExitCode
+	}
+	if end.Aid != "" {
+		event.Aid = end.Aid
+	}
+	s.commandStart = time.Time{}
+	s.commandAid = ""
+	s.eventManager.EmitEvent(&Event{
+		Type: EventTypeCommandEnd,
+		Data: event,
+	})
+}
+
+// retrackCursorPin releases the given pin, if any, and returns a new pin
+// tracking the cursor.
+func (s *StreamHandler) retrackCursorPin(pin *pagelist.Pin) *pagelist.Pin {
+	if pin != nil {
+		s.terminal.UntrackPin(pin)
+	}
+	return s.terminal.TrackCursorPin()
+}
+
+func (s *StreamHandler) untrackPromptPins() {
+	if s.promptStart != nil {
+		s.terminal.UntrackPin(s.promptStart)
+		s.promptStart = nil
+	}
+	if s.inputStart != nil {
+		s.terminal.UntrackPin(s.inputStart)
+		s.inputStart = nil
+	}
+}
+
+func (s *StreamHandler) cursorPosition() (x, y int) {
+	return int(s.terminal.Screen.Cursor.X), int(s.terminal.Screen.Cursor.Y)
+}
+
 // ---------------- IGNORE THIS ----------------
 var _ streamHandler = (*StreamHandler)(nil)
 
@@ -296,6 +976,19 @@ type streamHandler interface {
 	handler.PrintHandler
 	handler.SGRHandler
 	handler.VT100Handler
+	handler.WindowOperationHandler
+	handler.WindowTitleHandler
+	handler.ReportPwdHandler
+	handler.SemanticPromptHandler
+	handler.HyperlinkHandler
+	handler.ClipboardHandler
+	handler.ColorOperationHandler
+	handler.DeviceReportHandler
+	handler.MarginHandler
+	handler.CursorStateHandler
+	handler.CursorStyleHandler
+	handler.CharsetHandler
+	handler.KeyboardHandler
 }
 
 // ---------------- IGNORE THIS ----------------
@@ -0,0 +1,141 @@
+// Package charsets implements the character sets a program can designate
+// and invoke with the ISO 2022 escape sequences, e.g. the DEC special
+// graphics set used to draw lines by ncurses and tmux.
+//
+// See: https://vt100.net/docs/vt220-rm/chapter4.html
+package charsets
+
+// The character sets we support.
+type Charset uint8
+
+const (
+	CharsetUTF8       Charset = iota // Characters are printed as is
+	CharsetASCII                     // US ASCII, same as UTF-8 for us
+	CharsetBritish                   // UK, # is replaced with the pound sign
+	CharsetDECSpecial                // DEC special graphics (line drawing)
+)
+
+// The slots a character set can be designated to.
+type Slot uint8
+
+const (
+	SlotG0 Slot = iota
+	SlotG1
+	SlotG2
+	SlotG3
+)
+
+// The active areas a slot can be invoked into: GL for the codes 0x20-0x7F
+// and GR for 0xA0-0xFF.
+type ActiveSlot uint8
+
+const (
+	ActiveSlotGL ActiveSlot = iota
+	ActiveSlotGR
+)
+
+// Map the codepoint through the character set. Codepoints the set doesn't
+// replace are returned as is.
+func (c Charset) Map(cp uint32) uint32 {
+	switch c {
+	case CharsetBritish:
+		if cp == '#' {
+			return 0x00A3
+		}
+	case CharsetDECSpecial:
+		if cp >= 0x5F && cp <= 0x7E {
+			return decSpecial[cp-0x5F]
+		}
+	}
+	return cp
+}
+
+// The DEC special graphics set replaces the codes 0x5F-0x7E.
+//
+// See: https://en.wikipedia.org/wiki/DEC_Special_Graphics
+var decSpecial = [...]uint32{
+	0x0020, // _ blank
+	0x25C6, // ` diamond
+	0x2592, // a checkerboard
+	0x2409, // b HT
+	0x240C, // c FF
+	0x240D, // d CR
+	0x240A, // e LF
+	0x00B0, // f degree
+	0x00B1, // g plus/minus
+	0x2424, // h NL
+	0x240B, // i VT
+	0x2518, // j lower-right corner
+	0x2510, // k upper-right corner
+	0x250C, // l upper-left corner
+	0x2514, // m lower-left corner
+	0x253C, // n crossing lines
+	0x23BA, // o scan line 1
+	0x23BB, // p scan line 3
+	0x2500, // q horizontal line (scan line 5)
+	0x23BC, // r scan line 7
+	0x23BD, // s scan line 9
+	0x251C, // t left tee
+	0x2524, // u right tee
+	0x2534, // v bottom tee
+	0x252C, // w top tee
+	0x2502, // x vertical line
+	0x2264, // y less than or equal
+	0x2265, // z greater than or equal
+	0x03C0, // { pi
+	0x2260, // | not equal
+	0x00A3, // } pound sign
+	0x00B7, // ~ centered dot
+}
+
+// The character set state of a screen: the sets designated to each slot and
+// the slots invoked into GL and GR.
+type State struct {
+	Charsets [4]Charset
+	GL, GR   Slot
+
+	// The slot used for the next printed character only (SS2, SS3), nil if
+	// there is no single shift pending.
+	SingleShift *Slot
+}
+
+// Return the initial state: every slot holds UTF-8, G0 is invoked into GL
+// and G2 into GR.
+func NewState() State {
+	return State{GL: SlotG0, GR: SlotG2}
+}
+
+// Designate the character set to the slot.
+func (s *State) Configure(slot Slot, set Charset) {
+	s.Charsets[slot] = set
+}
+
+// Invoke the slot into the active area. A single shift only applies to the
+// next printed character and always invokes into GL.
+func (s *State) Invoke(active ActiveSlot, slot Slot, single bool) {
+	if single {
+		s.SingleShift = &slot
+		return
+	}
+	switch active {
+	case ActiveSlotGL:
+		s.GL = slot
+	case ActiveSlotGR:
+		s.GR = slot
+	}
+}
+
+// Map a printed codepoint through the character set invoked into GL,
+// consuming a pending single shift.
+func (s *State) Map(cp uint32) uint32 {
+	slot := s.GL
+	if s.SingleShift != nil {
+		slot = *s.SingleShift
+		s.SingleShift = nil
+	}
+	// Only the 7-bit codes are in the tables.
+	if cp > 0x7F {
+		return cp
+	}
+	return s.Charsets[slot].Map(cp)
+}
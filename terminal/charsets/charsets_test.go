@@ -0,0 +1,42 @@
+package charsets
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCharsetMap(t *testing.T) {
+	assert.Equal(t, uint32('q'), CharsetUTF8.Map('q'))
+	assert.Equal(t, uint32('#'), CharsetASCII.Map('#'))
+	assert.Equal(t, uint32('£'), CharsetBritish.Map('#'))
+	assert.Equal(t, uint32('q'), CharsetBritish.Map('q'))
+	assert.Equal(t, uint32('─'), CharsetDECSpecial.Map('q'))
+	assert.Equal(t, uint32('│'), CharsetDECSpecial.Map('x'))
+	assert.Equal(t, uint32('·'), CharsetDECSpecial.Map('~'))
+	assert.Equal(t, uint32('A'), CharsetDECSpecial.Map('A'))
+}
+
+func TestStateInvoke(t *testing.T) {
+	state := NewState()
+	state.Configure(SlotG1, CharsetDECSpecial)
+	state.Configure(SlotG2, CharsetBritish)
+	assert.Equal(t, uint32('q'), state.Map('q'))
+
+	// Locking shifts stay until the next shift.
+	state.Invoke(ActiveSlotGL, SlotG1, false)
+	assert.Equal(t, uint32('┌'), state.Map('l'))
+	assert.Equal(t, uint32('┐'), state.Map('k'))
+
+	// Single shifts apply to the next character only.
+	state.Invoke(ActiveSlotGL, SlotG2, true)
+	assert.Equal(t, uint32('£'), state.Map('#'))
+	assert.Equal(t, uint32('#'), state.Map('#'))
+	assert.Equal(t, uint32('┘'), state.Map('j'))
+
+	// Codepoints outside of 7-bit are never mapped.
+	assert.Equal(t, uint32('é'), state.Map('é'))
+
+	state.Invoke(ActiveSlotGL, SlotG0, false)
+	assert.Equal(t, uint32('j'), state.Map('j'))
+}
@@ -0,0 +1,75 @@
+package color
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidSpec = errors.New("invalid color specification")
+
+// Spec formats the color as an X11 rgb:rrrr/gggg/bbbb specification, which
+// is the format xterm uses to reply to dynamic color queries.
+func (c RGB) Spec() string {
+	return fmt.Sprintf("rgb:%02x%02x/%02x%02x/%02x%02x", c.R, c.R, c.G, c.G, c.B, c.B)
+}
+
+// ParseSpec parses an X11 color specification as used by the dynamic
+// color OSCs. The following formats are supported:
+//
+//   - rgb:<red>/<green>/<blue> where each component has 1 to 4 hex digits
+//   - #rgb, #rrggbb, #rrrgggbbb and #rrrrggggbbbb
+//
+// Components are scaled to 8 bits.
+func ParseSpec(spec string) (RGB, error) {
+	switch {
+	case strings.HasPrefix(spec, "rgb:"):
+		parts := strings.Split(spec[len("rgb:"):], "/")
+		if len(parts) != 3 {
+			return RGB{}, ErrInvalidSpec
+		}
+		var components [3]uint8
+		for i, part := range parts {
+			c, err := parseSpecComponent(part)
+			if err != nil {
+				return RGB{}, err
+			}
+			components[i] = c
+		}
+		return RGB{components[0], components[1], components[2]}, nil
+
+	case strings.HasPrefix(spec, "#"):
+		hex := spec[1:]
+		if len(hex) == 0 || len(hex)%3 != 0 || len(hex) > 12 {
+			return RGB{}, ErrInvalidSpec
+		}
+		width := len(hex) / 3
+		var components [3]uint8
+		for i := range 3 {
+			c, err := parseSpecComponent(hex[i*width : (i+1)*width])
+			if err != nil {
+				return RGB{}, err
+			}
+			components[i] = c
+		}
+		return RGB{components[0], components[1], components[2]}, nil
+
+	default:
+		return RGB{}, ErrInvalidSpec
+	}
+}
+
+// parseSpecComponent parses 1 to 4 hex digits and scales the value to 8
+// bits, e.g. "f" -> 0xFF, "80" -> 0x80, "ffff" -> 0xFF.
+func parseSpecComponent(s string) (uint8, error) {
+	if len(s) < 1 || len(s) > 4 {
+		return 0, ErrInvalidSpec
+	}
+	v, err := strconv.ParseUint(s, 16, 16)
+	if err != nil {
+		return 0, ErrInvalidSpec
+	}
+	maxValue := uint64(1)<<(4*len(s)) - 1
+	return uint8(v * 0xFF / maxValue), nil
+}
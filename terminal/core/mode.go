@@ -12,6 +12,9 @@ type Mode struct {
 	/// True if this is an ANSI mode
 	Ansi    bool
 	Default bool
+	// True if the mode is recognized but can't be changed, it always has
+	// its default value
+	Permanent bool
 }
 
 func entryForMode(name string, value int, ansi bool, defaultMode bool) Mode {
@@ -23,18 +26,42 @@ func entryForMode(name string, value int, ansi bool, defaultMode bool) Mode {
 	}
 }
 
+func permanentEntryForMode(name string, value int, ansi bool, defaultMode bool) Mode {
+	mode := entryForMode(name, value, ansi, defaultMode)
+	mode.Permanent = true
+	return mode
+}
+
 var (
 	// ansi modes
-	ModeError           = entryForMode("error (ignored)", 0, true, false)       // Error mode, typically ignored
-	ModeDisableKeyboard = entryForMode("disable Keyboard", 2, true, false)     // KAM
-	ModeInsert          = entryForMode("insert", 4, true, false)               // IRM
-	ModeSendReceiveMode = entryForMode("send_receive_mode", 12, true, true)    // SRM
-	ModeLineFeed        = entryForMode("line feed", 20, true, true)            // LNM
+	ModeError           = entryForMode("error (ignored)", 0, true, false)   // Error mode, typically ignored
+	ModeDisableKeyboard = entryForMode("disable Keyboard", 2, true, false)  // KAM
+	ModeInsert          = entryForMode("insert", 4, true, false)            // IRM
+	ModeSendReceiveMode = entryForMode("send_receive_mode", 12, true, true) // SRM
+	ModeLineFeed        = entryForMode("line feed", 20, true, true)         // LNM
 
 	// DEC modes
-	ModeWraparound    = entryForMode("wraparound", 7, false, true)     // DECCWM
-	ModeOrigin        = entryForMode("origin", 6, false, false)        // DECOM
-	ModeBracketedPaste = entryForMode("bracketed paste", 2004, false, false) // Bracketed paste mode
+	ModeCursorKeys               = entryForMode("cursor keys", 1, false, false)                   // DECCKM
+	ModeANSI                     = permanentEntryForMode("ansi", 2, false, true)                  // DECANM, we have no VT52 mode
+	ModeWraparound               = entryForMode("wraparound", 7, false, true)                     // DECCWM
+	ModeOrigin                   = entryForMode("origin", 6, false, false)                        // DECOM
+	ModeMouseX10                 = entryForMode("mouse x10", 9, false, false)                     // Mouse presses only
+	ModeCursorVisible            = entryForMode("cursor visible", 25, false, true)                // DECTCEM
+	ModeKeypadKeys               = entryForMode("keypad keys", 66, false, false)                  // DECNKM, also set by DECKPAM/DECKPNM
+	ModeEnableLeftAndRightMargin = entryForMode("enable left and right margin", 69, false, false) // DECLRMM
+	ModeAltScreenLegacy          = entryForMode("alt screen legacy", 47, false, false)            // Alternate screen
+	ModeAltScreen                = entryForMode("alt screen", 1047, false, false)                 // Alternate screen, cleared on exit
+	ModeAltScreenSaveCursor      = entryForMode("alt screen save cursor", 1049, false, false)     // Alternate screen with saved cursor, cleared on enter
+	ModeMouseNormal              = entryForMode("mouse normal", 1000, false, false)               // Mouse presses and releases
+	ModeMouseButton              = entryForMode("mouse button event", 1002, false, false)         // Also the motion while a button is held
+	ModeMouseAny                 = entryForMode("mouse any event", 1003, false, false)            // Also all the motion
+	ModeFocusEvent               = entryForMode("focus event", 1004, false, false)                // Report focus in and out
+	ModeMouseFormatUTF8          = entryForMode("mouse format utf8", 1005, false, false)          // Coordinates as UTF-8
+	ModeMouseFormatSGR           = entryForMode("mouse format sgr", 1006, false, false)           // CSI < b ; x ; y M/m
+	ModeAlternateScroll          = entryForMode("alternate scroll", 1007, false, false)           // Wheel sends arrows on the alternate screen
+	ModeMouseFormatURxvt         = entryForMode("mouse format urxvt", 1015, false, false)         // CSI b ; x ; y M
+	ModeMouseFormatSGRPixels     = entryForMode("mouse format sgr pixels", 1016, false, false)    // SGR with the position in pixels
+	ModeBracketedPaste           = entryForMode("bracketed paste", 2004, false, false)            // Bracketed paste mode
 
 	// The full list of avialbe entries. For documentation on these modes, see
 	// how they are used in the VT100 and ECMA-48 standards or google their values.
@@ -44,10 +71,45 @@ var (
 		ModeInsert,
 		ModeSendReceiveMode,
 		ModeLineFeed,
+		ModeCursorKeys,
+		ModeANSI,
 		ModeWraparound,
 		ModeOrigin,
+		ModeMouseX10,
+		ModeCursorVisible,
+		ModeKeypadKeys,
+		ModeEnableLeftAndRightMargin,
+		ModeAltScreenLegacy,
+		ModeAltScreen,
+		ModeAltScreenSaveCursor,
+		ModeMouseNormal,
+		ModeMouseButton,
+		ModeMouseAny,
+		ModeFocusEvent,
+		ModeMouseFormatUTF8,
+		ModeMouseFormatSGR,
+		ModeAlternateScroll,
+		ModeMouseFormatURxvt,
+		ModeMouseFormatSGRPixels,
 		ModeBracketedPaste,
 	}
+
+	// The mouse tracking modes, at most one of them is set.
+	MouseTrackingModes = []Mode{
+		ModeMouseX10,
+		ModeMouseNormal,
+		ModeMouseButton,
+		ModeMouseAny,
+	}
+
+	// The mouse format modes, at most one of them is set. The X10 format is
+	// used if none is.
+	MouseFormatModes = []Mode{
+		ModeMouseFormatUTF8,
+		ModeMouseFormatSGR,
+		ModeMouseFormatURxvt,
+		ModeMouseFormatSGRPixels,
+	}
 )
 
 // A Packed map of all settable modes. This shouldn't be used directly but
@@ -65,12 +127,20 @@ type ModeState struct {
 	values map[Mode]bool
 	// The default values of modes
 	defaults map[Mode]bool
+	// The values saved with XTSAVE
+	saved map[Mode]bool
+	// The xterm modifyOtherKeys level, this isn't a mode that can be set
+	// with SM/RM but it changes how keys are reported like one.
+	modifyOtherKeys ModifyOtherKeys
 }
 
+// Create the mode state. The maps are copied, changing the modes doesn't
+// change the given maps.
 func NewModeState(values map[Mode]bool, def map[Mode]bool) *ModeState {
 	state := &ModeState{
-		defaults: def,
-		values:   values,
+		defaults: maps.Clone(def),
+		values:   maps.Clone(values),
+		saved:    make(map[Mode]bool),
 	}
 	if values == nil {
 		state.values = make(map[Mode]bool)
@@ -81,17 +151,87 @@ func NewModeState(values map[Mode]bool, def map[Mode]bool) *ModeState {
 	return state
 }
 
+// Set the value of the mode, permanent modes are left as is.
 func (s *ModeState) Set(m Mode, value bool) {
+	if m.Permanent {
+		return
+	}
 	s.values[m] = value
 }
 
 func (s *ModeState) Get(m Mode) bool {
+	if m.Permanent {
+		return m.Default
+	}
 	return s.values[m]
 }
 
+// Reset the modes to their defaults and forget the saved values.
 func (s *ModeState) Reset() {
 	s.values = make(map[Mode]bool)
 	maps.Copy(s.values, s.defaults)
+	s.saved = make(map[Mode]bool)
+	s.modifyOtherKeys = ModifyOtherKeysNone
+}
+
+// How keys with modifiers are reported, set with XTMODKEYS
+// (CSI > 4 ; Pv m).
+//
+// See: https://invisible-island.net/xterm/modified-keys.html
+type ModifyOtherKeys uint8
+
+const (
+	ModifyOtherKeysNone            ModifyOtherKeys = 0 // Keys are reported as usual
+	ModifyOtherKeysExceptWellKnown ModifyOtherKeys = 1 // Except the keys with a well-known control code
+	ModifyOtherKeysAll             ModifyOtherKeys = 2 // All the keys with modifiers
+)
+
+func (s *ModeState) SetModifyOtherKeys(level ModifyOtherKeys) {
+	s.modifyOtherKeys = level
+}
+
+func (s *ModeState) ModifyOtherKeys() ModifyOtherKeys {
+	return s.modifyOtherKeys
+}
+
+// Save the current value of the mode (XTSAVE), saving again overwrites it.
+func (s *ModeState) Save(m Mode) {
+	s.saved[m] = s.Get(m)
+}
+
+// Return the value saved for the mode and whether there is one. The value
+// stays saved, the caller sets it so the side effects of the mode apply
+// (XTRESTORE).
+func (s *ModeState) Saved(m Mode) (value bool, ok bool) {
+	value, ok = s.saved[m]
+	return value, ok
+}
+
+// The state of a mode as reported by DECRPM.
+type ModeReport uint8
+
+const (
+	ModeReportNotRecognized    ModeReport = 0
+	ModeReportSet              ModeReport = 1
+	ModeReportReset            ModeReport = 2
+	ModeReportPermanentlySet   ModeReport = 3
+	ModeReportPermanentlyReset ModeReport = 4
+)
+
+// Report the state of the mode for DECRQM.
+func (s *ModeState) Report(m Mode) ModeReport {
+	switch {
+	case m == ModeError:
+		return ModeReportNotRecognized
+	case m.Permanent && m.Default:
+		return ModeReportPermanentlySet
+	case m.Permanent:
+		return ModeReportPermanentlyReset
+	case s.Get(m):
+		return ModeReportSet
+	default:
+		return ModeReportReset
+	}
 }
 
 func ModeFromInt(input int, ansi bool) *Mode {
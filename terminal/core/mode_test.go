@@ -46,3 +46,43 @@ func TestModeFromInput(t *testing.T) {
 	assert.NotNil(t, mode)
 	assert.True(t, *mode == ModeInsert)
 }
+
+func TestModeStateCopiesMaps(t *testing.T) {
+	state := NewModeState(ModePacked, ModePacked)
+	state.Set(ModeInsert, true)
+	assert.False(t, ModePacked[ModeInsert])
+
+	state.Reset()
+	assert.False(t, state.Get(ModeInsert))
+}
+
+func TestModeStateSave(t *testing.T) {
+	state := NewModeState(ModePacked, ModePacked)
+	_, ok := state.Saved(ModeOrigin)
+	assert.False(t, ok)
+
+	state.Set(ModeOrigin, true)
+	state.Save(ModeOrigin)
+	state.Set(ModeOrigin, false)
+	value, ok := state.Saved(ModeOrigin)
+	assert.True(t, ok)
+	assert.True(t, value)
+
+	// The saved values are gone after a reset.
+	state.Reset()
+	_, ok = state.Saved(ModeOrigin)
+	assert.False(t, ok)
+}
+
+func TestModeStateReport(t *testing.T) {
+	state := NewModeState(ModePacked, ModePacked)
+	state.Set(ModeInsert, true)
+	assert.Equal(t, ModeReportSet, state.Report(ModeInsert))
+	assert.Equal(t, ModeReportReset, state.Report(ModeOrigin))
+	assert.Equal(t, ModeReportNotRecognized, state.Report(ModeError))
+
+	// Permanent modes can't be changed.
+	state.Set(ModeANSI, false)
+	assert.True(t, state.Get(ModeANSI))
+	assert.Equal(t, ModeReportPermanentlySet, state.Report(ModeANSI))
+}
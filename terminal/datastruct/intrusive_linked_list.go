@@ -49,6 +49,7 @@ func (l *IntrusiveLinkedList[T]) InsertBefore(node *Node[T], newNode *Node[T]) {
 		newNode.Prev = nil
 		l.First = newNode
 	}
+	node.Prev = newNode
 }
 
 // Insert a new node at the end of the list.
@@ -124,6 +125,17 @@ func (l *IntrusiveLinkedList[T]) Search(val T) *Node[T] {
 	return nil
 }
 
+// All returns an iterator over the data of the nodes, from first to last.
 func (l *IntrusiveLinkedList[T]) All() iter.Seq[T] {
-	return func(yield func(T) bool) {}
+	return func(yield func(T) bool) {
+		for node := l.First; node != nil; {
+			// Grab the next node first so the current one can be removed
+			// while iterating.
+			next := node.Next
+			if !yield(node.Data) {
+				return
+			}
+			node = next
+		}
+	}
 }
@@ -1,8 +1,11 @@
 package handler
 
 import (
+	"github.com/hnimtadd/termio/terminal/charsets"
 	"github.com/hnimtadd/termio/terminal/core"
+	"github.com/hnimtadd/termio/terminal/kitty"
 	"github.com/hnimtadd/termio/terminal/sequences/csi"
+	"github.com/hnimtadd/termio/terminal/sequences/osc"
 	"github.com/hnimtadd/termio/terminal/sgr"
 )
 
@@ -21,17 +24,109 @@ type (
 		ReverseIndex()
 		// TabSet sets one horizontal stop at the active position.
 		TabSet()
+		// TabClear clears the horizontal stop at the active position or
+		// all of them depending on mode.
+		TabClear(mode csi.TBCMode)
+		// TabReset sets the horizontal stops to every 8 columns.
+		TabReset()
 		// FullReset resets all attributes to their defaults.
 		FullReset()
+		// SoftReset resets the modes, the style, the scrolling region, the
+		// saved cursor and the character sets but keeps the screen contents
+		// (DECSTR).
+		SoftReset()
 	}
 
 	SGRHandler interface {
 		SetGraphicsRendition(sgr *sgr.Attribute)
 	}
+	// WindowOperationHandler handles the xterm window manipulation
+	// sequences (XTWINOPS, CSI Ps ; Ps ; Ps t) we support.
+	WindowOperationHandler interface {
+		// PushTitle saves the window title and/or icon name on the title
+		// stack (CSI 22 ; Ps t).
+		PushTitle(target osc.TitleTarget)
+		// PopTitle restores the window title and/or icon name from the
+		// title stack (CSI 23 ; Ps t).
+		PopTitle(target osc.TitleTarget)
+	}
+	// DeviceReportHandler answers the requests of the host about the
+	// terminal itself.
+	DeviceReportHandler interface {
+		// DeviceAttributes reports the identity of the terminal (DA1, DA2
+		// and DA3).
+		DeviceAttributes(req csi.DARequest)
+		// DeviceStatusReport reports the status of the terminal or the
+		// cursor position (DSR and DECXCPR).
+		DeviceStatusReport(req csi.DSRRequest)
+		// TabStopReport reports the horizontal stops (DECTABSR).
+		TabStopReport()
+		// RequestMode reports the state of the ANSI or DEC private mode
+		// with the given number (DECRQM).
+		RequestMode(mode int, ansi bool)
+	}
+	// MarginHandler handles the margins of the scrolling region. Margins
+	// are 1-indexed, 0 means the default margin (the edge of the screen).
+	MarginHandler interface {
+		// SetTopAndBottomMargin sets the top and bottom margins (DECSTBM).
+		SetTopAndBottomMargin(top, bottom uint16)
+		// SetLeftAndRightMargin sets the left and right margins (DECSLRM),
+		// this is ignored unless the DECLRMM mode is set.
+		SetLeftAndRightMargin(left, right uint16)
+		// SetLeftAndRightMarginAmbiguous handles CSI s without parameters,
+		// which is DECSLRM when the DECLRMM mode is set and SCOSC (save
+		// cursor) otherwise.
+		SetLeftAndRightMarginAmbiguous()
+	}
+	// CursorStateHandler saves and restores the state of the cursor.
+	CursorStateHandler interface {
+		// SaveCursor saves the cursor position, pending wrap state, style,
+		// origin mode and character sets (DECSC, SCOSC).
+		SaveCursor()
+		// RestoreCursor restores the state saved by SaveCursor (DECRC,
+		// SCORC).
+		RestoreCursor()
+	}
+	// CursorStyleHandler handles how the cursor is drawn.
+	CursorStyleHandler interface {
+		// SetCursorStyle sets the shape of the cursor and whether it
+		// blinks (DECSCUSR).
+		SetCursorStyle(style csi.CursorStyle)
+	}
+	// KeyboardHandler handles how the keys pressed by the user are
+	// reported to the program.
+	KeyboardHandler interface {
+		// SetModifyOtherKeys sets the xterm modifyOtherKeys level
+		// (XTMODKEYS).
+		SetModifyOtherKeys(level core.ModifyOtherKeys)
+		// PushKittyKeyboard pushes the kitty keyboard protocol flags on the
+		// stack of the screen (CSI > flags u).
+		PushKittyKeyboard(flags kitty.KeyFlags)
+		// PopKittyKeyboard pops n entries from the stack (CSI < n u).
+		PopKittyKeyboard(n uint16)
+		// SetKittyKeyboard changes the current flags (CSI = flags ; mode u).
+		SetKittyKeyboard(mode kitty.KeySetMode, flags kitty.KeyFlags)
+		// QueryKittyKeyboard reports the current flags (CSI ? u).
+		QueryKittyKeyboard()
+	}
+	// CharsetHandler handles the character sets of the ISO 2022 shifts.
+	CharsetHandler interface {
+		// ConfigureCharset designates the character set to the slot (SCS).
+		ConfigureCharset(slot charsets.Slot, set charsets.Charset)
+		// InvokeCharset invokes the slot into the active area (SI, SO,
+		// LS2, LS3, LS1R, LS2R, LS3R), for the next character only if
+		// single is set (SS2, SS3).
+		InvokeCharset(active charsets.ActiveSlot, slot charsets.Slot, single bool)
+	}
 	VT100Handler interface {
 		// SetMode sets the mode to the given value, if the mode is not
 		// settable, it skips.
 		SetMode(mode core.Mode, value bool)
+		// SaveMode saves the value of the DEC private mode (XTSAVE).
+		SaveMode(mode core.Mode)
+		// RestoreMode sets the DEC private mode to the value saved by
+		// SaveMode, if any (XTRESTORE).
+		RestoreMode(mode core.Mode)
 	}
 	// EditorHandler interface includes all cursor movement and content
 	// related methods
@@ -52,6 +147,15 @@ type (
 		EraseInLine(mode csi.ELMode)
 		// EraseInDisplay erases chars in display with behavior depends on mode
 		EraseInDisplay(erase csi.EDMode)
+		// EraseChars erases chars repeated time start at the current cursor
+		// position rightward, without shifting the rest of the line
+		EraseChars(repeated uint16)
+		// ScrollUp scrolls the lines of the scrolling region up repeated
+		// times, adding blank lines at the bottom
+		ScrollUp(repeated uint16)
+		// ScrollDown scrolls the lines of the scrolling region down
+		// repeated times, adding blank lines at the top
+		ScrollDown(repeated uint16)
 		// LineFeed moves cursor to the first position of next line,
 		LineFeed()
 		// Backspace moves cursor to the left one character position,
@@ -61,6 +165,12 @@ type (
 		SetCursorRow(row uint16)
 		// SetCursorCol moves cursor to cols
 		SetCursorCol(col uint16)
+		// SetCursorRowRelative moves cursor down by offset rows, keeping
+		// the column
+		SetCursorRowRelative(offset uint16)
+		// SetCursorColRelative moves cursor right by offset cols, keeping
+		// the row
+		SetCursorColRelative(offset uint16)
 		// SetCursorPosition moves cursor to row and col
 		SetCursorPosition(row, col uint16)
 		// SetCursorUp moves cursor up by offset, carriage controls whether
@@ -0,0 +1,58 @@
+package handler
+
+import "github.com/hnimtadd/termio/terminal/sequences/osc"
+
+type (
+	WindowTitleHandler interface {
+		// ChangeWindowTitle changes the window title, the icon name or both
+		// depends on the target (OSC 0, OSC 1, OSC 2).
+		ChangeWindowTitle(target osc.TitleTarget, title string)
+	}
+
+	ReportPwdHandler interface {
+		// ReportPwd reports the current working directory of the shell as
+		// a file:// URI (OSC 7).
+		ReportPwd(uri string)
+	}
+
+	HyperlinkHandler interface {
+		// StartHyperlink starts a hyperlink, all printed cells belong to the
+		// link until EndHyperlink is called (OSC 8 ; params ; URI).
+		StartHyperlink(uri string, id string)
+		// EndHyperlink ends the current hyperlink (OSC 8 ; ;).
+		EndHyperlink()
+	}
+
+	ColorOperationHandler interface {
+		// ColorOperation sets, queries or resets the dynamic colors (OSC 4,
+		// OSC 10-12, OSC 104, OSC 110-112). Replies to queries must use the
+		// given terminator.
+		ColorOperation(ops []osc.ColorOperation, terminator osc.Terminator)
+	}
+
+	ClipboardHandler interface {
+		// ClipboardContents sets the clipboard to the base64 encoded data or
+		// reads it when data is "?" (OSC 52). Replies to read requests must
+		// use the given terminator.
+		ClipboardContents(kind uint8, data string, terminator osc.Terminator)
+	}
+
+	SemanticPromptHandler interface {
+		// PromptStart marks the start of a prompt (OSC 133 ; A).
+		PromptStart(prompt osc.PromptStart)
+		// PromptEnd marks the end of a prompt and the start of the user
+		// input (OSC 133 ; B).
+		PromptEnd()
+		// EndOfInput marks the end of the user input and the start of the
+		// command output (OSC 133 ; C).
+		EndOfInput()
+		// EndOfCommand marks the end of the command output (OSC 133 ; D).
+		EndOfCommand(end osc.EndOfCommand)
+	}
+
+	NotificationHandler interface {
+		// ShowDesktopNotification shows a desktop notification (OSC 9,
+		// OSC 777 ; notify).
+		ShowDesktopNotification(title, body string)
+	}
+)
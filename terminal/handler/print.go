@@ -1,3 +1,8 @@
 package handler
 
-type PrintHandler interface{ Print(c uint32) }
+type PrintHandler interface {
+	Print(c uint32)
+	// PrintRepeat prints the previous printed character repeated times
+	// (REP).
+	PrintRepeat(repeated uint16)
+}
@@ -0,0 +1,222 @@
+package input
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/hnimtadd/termio/terminal/core"
+	"github.com/hnimtadd/termio/terminal/kitty"
+)
+
+// Encoder turns the keys pressed by the user into the bytes the program
+// running in the terminal expects. The sequences follow xterm unless the
+// program enabled the kitty keyboard protocol.
+//
+// See: https://invisible-island.net/xterm/ctlseqs/ctlseqs.html#h2-PC-Style-Function-Keys
+type Encoder struct {
+	// The modes of the terminal the keys are sent to.
+	Modes *core.ModeState
+
+	// The current kitty keyboard protocol flags of the screen.
+	KittyFlags kitty.KeyFlags
+
+	// Whether the alternate screen is active, the wheel sends arrows there
+	// in the alternate scroll mode (1007).
+	AltScreen bool
+}
+
+// Encode the key event, nil is returned if the key produces nothing.
+func (e *Encoder) Encode(event KeyEvent) []byte {
+	if e.KittyFlags != kitty.KeyFlagsNone {
+		return e.kitty(event)
+	}
+	// Only the kitty keyboard protocol reports releases.
+	if event.Action == ActionRelease {
+		return nil
+	}
+	return e.legacy(event)
+}
+
+// Encode the key event with the xterm sequences.
+func (e *Encoder) legacy(event KeyEvent) []byte {
+	switch event.Key {
+	case KeyUp:
+		return e.cursorKey('A', event.Mods)
+	case KeyDown:
+		return e.cursorKey('B', event.Mods)
+	case KeyRight:
+		return e.cursorKey('C', event.Mods)
+	case KeyLeft:
+		return e.cursorKey('D', event.Mods)
+	case KeyHome:
+		return e.cursorKey('H', event.Mods)
+	case KeyEnd:
+		return e.cursorKey('F', event.Mods)
+
+	case KeyInsert:
+		return tildeKey(2, event.Mods)
+	case KeyDelete:
+		return tildeKey(3, event.Mods)
+	case KeyPageUp:
+		return tildeKey(5, event.Mods)
+	case KeyPageDown:
+		return tildeKey(6, event.Mods)
+
+	case KeyF1, KeyF2, KeyF3, KeyF4:
+		final := "PQRS"[event.Key-KeyF1]
+		if event.Mods == 0 {
+			return []byte{0x1b, 'O', final}
+		}
+		return fmt.Appendf(nil, "\x1b[1;%d%c", event.Mods.param(), final)
+	case KeyF5, KeyF6, KeyF7, KeyF8, KeyF9, KeyF10, KeyF11, KeyF12:
+		// The numbers skip 16 and 22.
+		numbers := [...]int{15, 17, 18, 19, 20, 21, 23, 24}
+		return tildeKey(numbers[event.Key-KeyF5], event.Mods)
+
+	case KeyEnter:
+		return e.controlKey('\r', event.Mods)
+	case KeyTab:
+		if e.Modes.ModifyOtherKeys() != core.ModifyOtherKeysAll && event.Mods == ModShift {
+			// CBT, back tab
+			return []byte("\x1b[Z")
+		}
+		return e.controlKey('\t', event.Mods)
+	case KeyBackspace:
+		if event.Mods&ModCtrl != 0 && e.Modes.ModifyOtherKeys() != core.ModifyOtherKeysAll {
+			return e.controlKey(0x08, event.Mods&^ModCtrl)
+		}
+		return e.controlKey(0x7F, event.Mods)
+	case KeyEscape:
+		return e.controlKey(0x1b, event.Mods)
+	}
+
+	if key, ok := keypadKeys[event.Key]; ok {
+		if e.Modes.Get(core.ModeKeypadKeys) && event.Mods == 0 {
+			return []byte{0x1b, 'O', key.final}
+		}
+		if event.Key == KeyKpEnter {
+			return e.controlKey('\r', event.Mods)
+		}
+		event.Text = key.text
+	}
+	return e.text(event)
+}
+
+// The text and the final of the application mode sequence of the keypad
+// keys.
+var keypadKeys = map[Key]struct {
+	text  string
+	final byte
+}{
+	KeyKp0:        {"0", 'p'},
+	KeyKp1:        {"1", 'q'},
+	KeyKp2:        {"2", 'r'},
+	KeyKp3:        {"3", 's'},
+	KeyKp4:        {"4", 't'},
+	KeyKp5:        {"5", 'u'},
+	KeyKp6:        {"6", 'v'},
+	KeyKp7:        {"7", 'w'},
+	KeyKp8:        {"8", 'x'},
+	KeyKp9:        {"9", 'y'},
+	KeyKpDecimal:  {".", 'n'},
+	KeyKpDivide:   {"/", 'o'},
+	KeyKpMultiply: {"*", 'j'},
+	KeyKpSubtract: {"-", 'm'},
+	KeyKpAdd:      {"+", 'k'},
+	KeyKpEnter:    {"\r", 'M'},
+	KeyKpEqual:    {"=", 'X'},
+}
+
+// The arrows, home and end use SS3 in the cursor keys application mode
+// (DECCKM) unless a modifier is held.
+func (e *Encoder) cursorKey(final byte, mods Mods) []byte {
+	if mods != 0 {
+		return fmt.Appendf(nil, "\x1b[1;%d%c", mods.param(), final)
+	}
+	if e.Modes.Get(core.ModeCursorKeys) {
+		return []byte{0x1b, 'O', final}
+	}
+	return []byte{0x1b, '[', final}
+}
+
+// The editing keys and F5-F12 are CSI n ~.
+func tildeKey(number int, mods Mods) []byte {
+	if mods != 0 {
+		return fmt.Appendf(nil, "\x1b[%d;%d~", number, mods.param())
+	}
+	return fmt.Appendf(nil, "\x1b[%d~", number)
+}
+
+// Keys that send a control character, alt prefixes it with ESC.
+func (e *Encoder) controlKey(code byte, mods Mods) []byte {
+	if mods != 0 && e.Modes.ModifyOtherKeys() == core.ModifyOtherKeysAll {
+		return modifyOtherKeys(rune(code), mods)
+	}
+	if mods&ModAlt != 0 {
+		return []byte{0x1b, code}
+	}
+	return []byte{code}
+}
+
+// Encode the text of the key. Ctrl turns the text into its control
+// character, alt prefixes it with ESC. With modifyOtherKeys the keys with
+// modifiers are reported as CSI 27 ; mods ; code ~ instead, for all of them
+// or only for the ones that have no control character.
+func (e *Encoder) text(event KeyEvent) []byte {
+	r, size := utf8.DecodeRuneInString(event.Text)
+	if size == 0 {
+		return nil
+	}
+	// Shift is already applied to the text.
+	mods := event.Mods &^ ModShift
+	if size != len(event.Text) || mods == 0 {
+		return []byte(event.Text)
+	}
+
+	code, hasCode := controlCode(r)
+	switch e.Modes.ModifyOtherKeys() {
+	case core.ModifyOtherKeysAll:
+		return modifyOtherKeys(r, event.Mods)
+	case core.ModifyOtherKeysExceptWellKnown:
+		// ctrl+shift+a would send the same code as ctrl+a.
+		if mods&ModCtrl != 0 && (!hasCode || event.Mods&ModShift != 0) {
+			return modifyOtherKeys(r, event.Mods)
+		}
+	}
+
+	out := []byte(event.Text)
+	if mods&ModCtrl != 0 && hasCode {
+		out = []byte{code}
+	}
+	if mods&ModAlt != 0 {
+		out = append([]byte{0x1b}, out...)
+	}
+	return out
+}
+
+// The xterm modifyOtherKeys sequence.
+func modifyOtherKeys(r rune, mods Mods) []byte {
+	return fmt.Appendf(nil, "\x1b[27;%d;%d~", mods.param(), r)
+}
+
+// Return the control character sent for ctrl and the rune, following the
+// legacy xterm mappings.
+func controlCode(r rune) (byte, bool) {
+	switch {
+	case r >= 'a' && r <= 'z':
+		return byte(r-'a') + 1, true
+	case r >= '@' && r <= '_':
+		// @, A-Z, [, \, ], ^ and _
+		return byte(r - '@'), true
+	case r == ' ' || r == '2':
+		return 0x00, true
+	case r >= '3' && r <= '7':
+		return byte(r-'3') + 0x1b, true
+	case r == '8' || r == '?':
+		return 0x7F, true
+	case r == '/':
+		return 0x1F, true
+	default:
+		return 0, false
+	}
+}
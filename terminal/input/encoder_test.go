@@ -0,0 +1,103 @@
+package input
+
+import (
+	"testing"
+
+	"github.com/hnimtadd/termio/terminal/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncoder_CursorKeys(t *testing.T) {
+	encoder := Encoder{Modes: core.NewModeState(core.ModePacked, core.ModePacked)}
+	assert.Equal(t, "\x1b[A", string(encoder.Encode(KeyEvent{Key: KeyUp})))
+	assert.Equal(t, "\x1b[H", string(encoder.Encode(KeyEvent{Key: KeyHome})))
+	assert.Equal(t, "\x1b[1;5C", string(encoder.Encode(KeyEvent{Key: KeyRight, Mods: ModCtrl})))
+
+	// The application mode only changes the keys without modifiers.
+	encoder.Modes.Set(core.ModeCursorKeys, true)
+	assert.Equal(t, "\x1bOA", string(encoder.Encode(KeyEvent{Key: KeyUp})))
+	assert.Equal(t, "\x1bOF", string(encoder.Encode(KeyEvent{Key: KeyEnd})))
+	assert.Equal(t, "\x1b[1;2D", string(encoder.Encode(KeyEvent{Key: KeyLeft, Mods: ModShift})))
+}
+
+func TestEncoder_FunctionKeys(t *testing.T) {
+	encoder := Encoder{Modes: core.NewModeState(core.ModePacked, core.ModePacked)}
+	tests := []struct {
+		event    KeyEvent
+		expected string
+	}{
+		{KeyEvent{Key: KeyF1}, "\x1bOP"},
+		{KeyEvent{Key: KeyF4, Mods: ModShift}, "\x1b[1;2S"},
+		{KeyEvent{Key: KeyF5}, "\x1b[15~"},
+		{KeyEvent{Key: KeyF6}, "\x1b[17~"},
+		{KeyEvent{Key: KeyF12, Mods: ModCtrl | ModAlt}, "\x1b[24;7~"},
+		{KeyEvent{Key: KeyDelete}, "\x1b[3~"},
+		{KeyEvent{Key: KeyPageUp, Mods: ModSuper}, "\x1b[5;9~"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, string(encoder.Encode(tt.event)), "%+v", tt.event)
+	}
+}
+
+func TestEncoder_Keypad(t *testing.T) {
+	encoder := Encoder{Modes: core.NewModeState(core.ModePacked, core.ModePacked)}
+	assert.Equal(t, "5", string(encoder.Encode(KeyEvent{Key: KeyKp5})))
+	assert.Equal(t, "\r", string(encoder.Encode(KeyEvent{Key: KeyKpEnter})))
+
+	encoder.Modes.Set(core.ModeKeypadKeys, true)
+	assert.Equal(t, "\x1bOu", string(encoder.Encode(KeyEvent{Key: KeyKp5})))
+	assert.Equal(t, "\x1bOM", string(encoder.Encode(KeyEvent{Key: KeyKpEnter})))
+	assert.Equal(t, "\x1bOk", string(encoder.Encode(KeyEvent{Key: KeyKpAdd})))
+}
+
+func TestEncoder_Text(t *testing.T) {
+	encoder := Encoder{Modes: core.NewModeState(core.ModePacked, core.ModePacked)}
+	tests := []struct {
+		event    KeyEvent
+		expected string
+	}{
+		{KeyEvent{Text: "a"}, "a"},
+		{KeyEvent{Text: "A", Mods: ModShift}, "A"},
+		{KeyEvent{Text: "é"}, "é"},
+		{KeyEvent{Text: "c", Mods: ModCtrl}, "\x03"},
+		{KeyEvent{Text: "[", Mods: ModCtrl}, "\x1b"},
+		{KeyEvent{Text: " ", Mods: ModCtrl}, "\x00"},
+		{KeyEvent{Text: "b", Mods: ModAlt}, "\x1bb"},
+		{KeyEvent{Text: "x", Mods: ModCtrl | ModAlt}, "\x1b\x18"},
+		{KeyEvent{Text: "1", Mods: ModCtrl}, "1"},
+		{KeyEvent{Key: KeyEnter}, "\r"},
+		{KeyEvent{Key: KeyEnter, Mods: ModAlt}, "\x1b\r"},
+		{KeyEvent{Key: KeyTab}, "\t"},
+		{KeyEvent{Key: KeyTab, Mods: ModShift}, "\x1b[Z"},
+		{KeyEvent{Key: KeyBackspace}, "\x7f"},
+		{KeyEvent{Key: KeyBackspace, Mods: ModCtrl}, "\x08"},
+		{KeyEvent{Key: KeyEscape}, "\x1b"},
+		{KeyEvent{}, ""},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, string(encoder.Encode(tt.event)), "%+v", tt.event)
+	}
+}
+
+func TestEncoder_ModifyOtherKeys(t *testing.T) {
+	encoder := Encoder{Modes: core.NewModeState(core.ModePacked, core.ModePacked)}
+
+	// Only the keys without a well-known control character are modified.
+	encoder.Modes.SetModifyOtherKeys(core.ModifyOtherKeysExceptWellKnown)
+	assert.Equal(t, "\x03", string(encoder.Encode(KeyEvent{Text: "c", Mods: ModCtrl})))
+	assert.Equal(t, "\x1b[27;5;49~", string(encoder.Encode(KeyEvent{Text: "1", Mods: ModCtrl})))
+	assert.Equal(t, "\x1b[27;6;67~", string(encoder.Encode(KeyEvent{Text: "C", Mods: ModCtrl | ModShift})))
+	assert.Equal(t, "\x1bc", string(encoder.Encode(KeyEvent{Text: "c", Mods: ModAlt})))
+	assert.Equal(t, "\r", string(encoder.Encode(KeyEvent{Key: KeyEnter, Mods: ModCtrl})))
+
+	encoder.Modes.SetModifyOtherKeys(core.ModifyOtherKeysAll)
+	assert.Equal(t, "\x1b[27;5;99~", string(encoder.Encode(KeyEvent{Text: "c", Mods: ModCtrl})))
+	assert.Equal(t, "\x1b[27;3;99~", string(encoder.Encode(KeyEvent{Text: "c", Mods: ModAlt})))
+	assert.Equal(t, "\x1b[27;5;13~", string(encoder.Encode(KeyEvent{Key: KeyEnter, Mods: ModCtrl})))
+	assert.Equal(t, "\x1b[27;2;9~", string(encoder.Encode(KeyEvent{Key: KeyTab, Mods: ModShift})))
+	assert.Equal(t, "\x1b[27;5;127~", string(encoder.Encode(KeyEvent{Key: KeyBackspace, Mods: ModCtrl})))
+
+	// Shift alone doesn't modify the text, the arrows keep their sequences.
+	assert.Equal(t, "A", string(encoder.Encode(KeyEvent{Text: "A", Mods: ModShift})))
+	assert.Equal(t, "\x1b[1;5A", string(encoder.Encode(KeyEvent{Key: KeyUp, Mods: ModCtrl})))
+}
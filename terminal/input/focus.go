@@ -0,0 +1,16 @@
+package input
+
+import "github.com/hnimtadd/termio/terminal/core"
+
+// EncodeFocus encodes the focus change of the terminal window, CSI I when it
+// gains the focus and CSI O when it loses it. Nil is returned unless the
+// program set the focus event mode (1004).
+func (e *Encoder) EncodeFocus(focused bool) []byte {
+	if !e.Modes.Get(core.ModeFocusEvent) {
+		return nil
+	}
+	if focused {
+		return []byte("\x1b[I")
+	}
+	return []byte("\x1b[O")
+}
@@ -0,0 +1,20 @@
+package input
+
+import (
+	"testing"
+
+	"github.com/hnimtadd/termio/terminal/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncoder_Focus(t *testing.T) {
+	encoder := Encoder{Modes: core.NewModeState(core.ModePacked, core.ModePacked)}
+
+	// Nothing is reported unless the program asked for it.
+	assert.Nil(t, encoder.EncodeFocus(true))
+	assert.Nil(t, encoder.EncodeFocus(false))
+
+	encoder.Modes.Set(core.ModeFocusEvent, true)
+	assert.Equal(t, "\x1b[I", string(encoder.EncodeFocus(true)))
+	assert.Equal(t, "\x1b[O", string(encoder.EncodeFocus(false)))
+}
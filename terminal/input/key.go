@@ -0,0 +1,136 @@
+// Package input encodes the keys pressed by the user into the bytes the
+// program running in the terminal expects, depending on the modes the
+// program set (DECCKM, DECKPAM, modifyOtherKeys, ...).
+package input
+
+import "unicode"
+
+// The keys that don't produce text by themselves or that are encoded
+// differently from the text they produce.
+type Key uint8
+
+const (
+	// A key that only produces text, e.g. a letter or a digit. The text of
+	// the event is encoded.
+	KeyUnidentified Key = iota
+
+	KeyEnter
+	KeyTab
+	KeyBackspace
+	KeyEscape
+
+	// Cursor and editing keys
+	KeyUp
+	KeyDown
+	KeyRight
+	KeyLeft
+	KeyHome
+	KeyEnd
+	KeyInsert
+	KeyDelete
+	KeyPageUp
+	KeyPageDown
+
+	// Function keys
+	KeyF1
+	KeyF2
+	KeyF3
+	KeyF4
+	KeyF5
+	KeyF6
+	KeyF7
+	KeyF8
+	KeyF9
+	KeyF10
+	KeyF11
+	KeyF12
+
+	// Keypad keys, they are encoded as their text unless the keypad
+	// application mode is set.
+	KeyKp0
+	KeyKp1
+	KeyKp2
+	KeyKp3
+	KeyKp4
+	KeyKp5
+	KeyKp6
+	KeyKp7
+	KeyKp8
+	KeyKp9
+	KeyKpDecimal
+	KeyKpDivide
+	KeyKpMultiply
+	KeyKpSubtract
+	KeyKpAdd
+	KeyKpEnter
+	KeyKpEqual
+)
+
+// The modifiers held while a key is pressed.
+type Mods uint8
+
+const (
+	ModShift Mods = 1 << iota
+	ModAlt
+	ModCtrl
+	ModSuper
+)
+
+// The modifier parameter of the xterm sequences, 1 plus the bits of the
+// modifiers.
+func (m Mods) param() int {
+	param := 1
+	if m&ModShift != 0 {
+		param += 1
+	}
+	if m&ModAlt != 0 {
+		param += 2
+	}
+	if m&ModCtrl != 0 {
+		param += 4
+	}
+	if m&ModSuper != 0 {
+		param += 8
+	}
+	return param
+}
+
+// What happened to the key.
+type Action uint8
+
+const (
+	ActionPress Action = iota
+	ActionRepeat
+	ActionRelease
+)
+
+// A key pressed by the user.
+type KeyEvent struct {
+	// The key that was pressed, KeyUnidentified for keys that only produce
+	// text.
+	Key Key
+
+	// The modifiers held while the key was pressed.
+	Mods Mods
+
+	// The text the key produces with shift applied but without ctrl and
+	// alt, e.g. "A" for shift+a and "a" for ctrl+a.
+	Text string
+
+	// Whether the key was pressed, repeated or released. Releases are only
+	// reported with the kitty keyboard protocol.
+	Action Action
+
+	// The codepoint of the key without shift, e.g. '1' for shift+1 ("!")
+	// on a US layout. This is the key code of the kitty keyboard protocol,
+	// the text is lowercased if this is 0.
+	UnshiftedCodepoint rune
+}
+
+// Return the codepoint of the key without shift.
+func (e KeyEvent) unshifted(r rune) rune {
+	if e.UnshiftedCodepoint != 0 {
+		return e.UnshiftedCodepoint
+	}
+	return unicode.ToLower(r)
+}
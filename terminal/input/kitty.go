@@ -0,0 +1,155 @@
+package input
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/hnimtadd/termio/terminal/kitty"
+)
+
+// The code and the final of the functional keys in the kitty keyboard
+// protocol.
+type kittyKey struct {
+	code  int
+	final byte
+}
+
+var kittyKeys = map[Key]kittyKey{
+	KeyEnter:     {13, 'u'},
+	KeyTab:       {9, 'u'},
+	KeyBackspace: {127, 'u'},
+	KeyEscape:    {27, 'u'},
+
+	KeyUp:       {1, 'A'},
+	KeyDown:     {1, 'B'},
+	KeyRight:    {1, 'C'},
+	KeyLeft:     {1, 'D'},
+	KeyHome:     {1, 'H'},
+	KeyEnd:      {1, 'F'},
+	KeyInsert:   {2, '~'},
+	KeyDelete:   {3, '~'},
+	KeyPageUp:   {5, '~'},
+	KeyPageDown: {6, '~'},
+
+	KeyF1:  {1, 'P'},
+	KeyF2:  {1, 'Q'},
+	KeyF3:  {13, '~'},
+	KeyF4:  {1, 'S'},
+	KeyF5:  {15, '~'},
+	KeyF6:  {17, '~'},
+	KeyF7:  {18, '~'},
+	KeyF8:  {19, '~'},
+	KeyF9:  {20, '~'},
+	KeyF10: {21, '~'},
+	KeyF11: {23, '~'},
+	KeyF12: {24, '~'},
+
+	KeyKp0:        {57399, 'u'},
+	KeyKp1:        {57400, 'u'},
+	KeyKp2:        {57401, 'u'},
+	KeyKp3:        {57402, 'u'},
+	KeyKp4:        {57403, 'u'},
+	KeyKp5:        {57404, 'u'},
+	KeyKp6:        {57405, 'u'},
+	KeyKp7:        {57406, 'u'},
+	KeyKp8:        {57407, 'u'},
+	KeyKp9:        {57408, 'u'},
+	KeyKpDecimal:  {57409, 'u'},
+	KeyKpDivide:   {57410, 'u'},
+	KeyKpMultiply: {57411, 'u'},
+	KeyKpSubtract: {57412, 'u'},
+	KeyKpAdd:      {57413, 'u'},
+	KeyKpEnter:    {57414, 'u'},
+	KeyKpEqual:    {57415, 'u'},
+}
+
+// Encode the key event with the kitty keyboard protocol:
+//
+//	CSI code[:shifted] ; mods[:event] ; text final
+//
+// Trailing parameters that have their default value are omitted, the
+// modifiers are left empty if only the text follows.
+//
+// See: https://sw.kovidgoyal.net/kitty/keyboard-protocol/
+func (e *Encoder) kitty(event KeyEvent) []byte {
+	flags := e.KittyFlags
+	reportAll := flags&kitty.KeyFlagReportAll != 0
+	if flags&kitty.KeyFlagReportEvents == 0 {
+		if event.Action == ActionRelease {
+			return nil
+		}
+		event.Action = ActionPress
+	}
+
+	key, functional := kittyKeys[event.Key]
+	_, keypad := keypadKeys[event.Key]
+	if !reportAll {
+		switch event.Key {
+		case KeyEnter, KeyTab, KeyBackspace:
+			// These keep working in a shell after a program that enabled
+			// the protocol crashed, their releases aren't reported.
+			if event.Action == ActionRelease {
+				return nil
+			}
+		}
+
+		// Only the keys that are ambiguous in the legacy encoding change:
+		// escape and the keys with modifiers. Shift is part of the text.
+		mods := event.Mods
+		if !functional || keypad {
+			mods &^= ModShift
+		}
+		if event.Action != ActionRelease && mods == 0 && event.Key != KeyEscape {
+			return e.legacy(event)
+		}
+	}
+
+	var shifted rune
+	var text string
+	if !functional {
+		r, size := utf8.DecodeRuneInString(event.Text)
+		if size == 0 {
+			return nil
+		}
+		key = kittyKey{code: int(event.unshifted(r)), final: 'u'}
+		if event.Mods&ModShift != 0 && r != rune(key.code) && size == len(event.Text) {
+			shifted = r
+		}
+		// Only the text of the keys that produce text, not of the ones
+		// turned into control characters.
+		if event.Mods&^ModShift == 0 && event.Action != ActionRelease && unicode.IsPrint(r) {
+			text = event.Text
+		}
+	}
+
+	var params [3]string
+	params[0] = fmt.Sprint(key.code)
+	if shifted != 0 && flags&kitty.KeyFlagReportAlternate != 0 {
+		params[0] += fmt.Sprintf(":%d", shifted)
+	}
+	if event.Mods != 0 || event.Action != ActionPress {
+		params[1] = fmt.Sprint(event.Mods.param())
+		if event.Action != ActionPress {
+			params[1] += fmt.Sprintf(":%d", event.Action+1)
+		}
+	}
+	if text != "" && reportAll && flags&kitty.KeyFlagReportText != 0 {
+		codepoints := make([]string, 0, len(text))
+		for _, r := range text {
+			codepoints = append(codepoints, fmt.Sprint(r))
+		}
+		params[2] = strings.Join(codepoints, ":")
+	}
+
+	n := len(params)
+	for n > 1 && params[n-1] == "" {
+		n--
+	}
+	// CSI 1 A is sent as CSI A.
+	if n == 1 && key.code == 1 && key.final != 'u' && key.final != '~' {
+		params[0] = ""
+	}
+	return fmt.Appendf(nil, "\x1b[%s%c", strings.Join(params[:n], ";"), key.final)
+}
@@ -0,0 +1,111 @@
+package input
+
+import (
+	"testing"
+
+	"github.com/hnimtadd/termio/terminal/core"
+	"github.com/hnimtadd/termio/terminal/kitty"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncoder_KittyDisambiguate(t *testing.T) {
+	encoder := Encoder{
+		Modes:      core.NewModeState(core.ModePacked, core.ModePacked),
+		KittyFlags: kitty.KeyFlagDisambiguate,
+	}
+	tests := []struct {
+		event    KeyEvent
+		expected string
+	}{
+		// Text, enter, tab and backspace are sent as usual.
+		{KeyEvent{Text: "a"}, "a"},
+		{KeyEvent{Text: "A", Mods: ModShift}, "A"},
+		{KeyEvent{Key: KeyEnter}, "\r"},
+		{KeyEvent{Key: KeyTab}, "\t"},
+		{KeyEvent{Key: KeyBackspace}, "\x7f"},
+		{KeyEvent{Key: KeyKp1}, "1"},
+		{KeyEvent{Key: KeyUp}, "\x1b[A"},
+		{KeyEvent{Key: KeyF1}, "\x1bOP"},
+
+		// Escape and the keys with modifiers are disambiguated.
+		{KeyEvent{Key: KeyEscape}, "\x1b[27u"},
+		{KeyEvent{Text: "a", Mods: ModCtrl}, "\x1b[97;5u"},
+		{KeyEvent{Text: "a", Mods: ModAlt}, "\x1b[97;3u"},
+		{KeyEvent{Text: "A", Mods: ModCtrl | ModShift}, "\x1b[97;6u"},
+		{KeyEvent{Text: "i", Mods: ModCtrl}, "\x1b[105;5u"},
+		{KeyEvent{Key: KeyEnter, Mods: ModShift}, "\x1b[13;2u"},
+		{KeyEvent{Key: KeyTab, Mods: ModShift}, "\x1b[9;2u"},
+		{KeyEvent{Key: KeyBackspace, Mods: ModCtrl}, "\x1b[127;5u"},
+		{KeyEvent{Key: KeyKp1, Mods: ModCtrl}, "\x1b[57400;5u"},
+		{KeyEvent{Key: KeyUp, Mods: ModCtrl}, "\x1b[1;5A"},
+		{KeyEvent{Key: KeyF3, Mods: ModShift}, "\x1b[13;2~"},
+		{KeyEvent{Key: KeyDelete, Mods: ModAlt}, "\x1b[3;3~"},
+
+		// Releases aren't reported.
+		{KeyEvent{Text: "a", Mods: ModCtrl, Action: ActionRelease}, ""},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, string(encoder.Encode(tt.event)), "%+v", tt.event)
+	}
+}
+
+func TestEncoder_KittyReportAll(t *testing.T) {
+	encoder := Encoder{
+		Modes:      core.NewModeState(core.ModePacked, core.ModePacked),
+		KittyFlags: kitty.KeyFlagDisambiguate | kitty.KeyFlagReportAll,
+	}
+	tests := []struct {
+		event    KeyEvent
+		expected string
+	}{
+		{KeyEvent{Text: "a"}, "\x1b[97u"},
+		{KeyEvent{Text: "A", Mods: ModShift}, "\x1b[97;2u"},
+		{KeyEvent{Text: "!", Mods: ModShift, UnshiftedCodepoint: '1'}, "\x1b[49;2u"},
+		{KeyEvent{Key: KeyEnter}, "\x1b[13u"},
+		{KeyEvent{Key: KeyTab}, "\x1b[9u"},
+		{KeyEvent{Key: KeyBackspace}, "\x1b[127u"},
+		{KeyEvent{Key: KeyKp1}, "\x1b[57400u"},
+		{KeyEvent{Key: KeyUp}, "\x1b[A"},
+		{KeyEvent{Key: KeyF1}, "\x1b[P"},
+		{KeyEvent{Key: KeyF3}, "\x1b[13~"},
+		{KeyEvent{Key: KeyPageDown}, "\x1b[6~"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, string(encoder.Encode(tt.event)), "%+v", tt.event)
+	}
+}
+
+func TestEncoder_KittyReportEvents(t *testing.T) {
+	encoder := Encoder{
+		Modes:      core.NewModeState(core.ModePacked, core.ModePacked),
+		KittyFlags: kitty.KeyFlagDisambiguate | kitty.KeyFlagReportEvents,
+	}
+	assert.Equal(t, "a", string(encoder.Encode(KeyEvent{Text: "a"})))
+	assert.Equal(t, "a", string(encoder.Encode(KeyEvent{Text: "a", Action: ActionRepeat})))
+	assert.Equal(t, "\x1b[97;1:3u", string(encoder.Encode(KeyEvent{Text: "a", Action: ActionRelease})))
+	assert.Equal(t, "\x1b[97;5:2u", string(encoder.Encode(KeyEvent{Text: "a", Mods: ModCtrl, Action: ActionRepeat})))
+	assert.Equal(t, "\x1b[1;1:3A", string(encoder.Encode(KeyEvent{Key: KeyUp, Action: ActionRelease})))
+
+	// Enter, tab and backspace only report releases with all the keys as
+	// escape codes.
+	assert.Equal(t, "", string(encoder.Encode(KeyEvent{Key: KeyEnter, Action: ActionRelease})))
+	encoder.KittyFlags |= kitty.KeyFlagReportAll
+	assert.Equal(t, "\x1b[13;1:3u", string(encoder.Encode(KeyEvent{Key: KeyEnter, Action: ActionRelease})))
+}
+
+func TestEncoder_KittyAlternateAndText(t *testing.T) {
+	encoder := Encoder{
+		Modes:      core.NewModeState(core.ModePacked, core.ModePacked),
+		KittyFlags: kitty.KeyFlagDisambiguate | kitty.KeyFlagReportAlternate,
+	}
+	assert.Equal(t, "\x1b[97:65;6u", string(encoder.Encode(KeyEvent{Text: "A", Mods: ModCtrl | ModShift})))
+
+	encoder.KittyFlags = kitty.KeyFlagReportAll | kitty.KeyFlagReportAlternate
+	assert.Equal(t, "\x1b[97:65;2u", string(encoder.Encode(KeyEvent{Text: "A", Mods: ModShift})))
+
+	encoder.KittyFlags = kitty.KeyFlagReportAll | kitty.KeyFlagReportText
+	assert.Equal(t, "\x1b[97;;97u", string(encoder.Encode(KeyEvent{Text: "a"})))
+	assert.Equal(t, "\x1b[97;2;65u", string(encoder.Encode(KeyEvent{Text: "A", Mods: ModShift})))
+	assert.Equal(t, "\x1b[97;5u", string(encoder.Encode(KeyEvent{Text: "a", Mods: ModCtrl})))
+	assert.Equal(t, "\x1b[13u", string(encoder.Encode(KeyEvent{Key: KeyEnter})))
+}
@@ -0,0 +1,201 @@
+package input
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/hnimtadd/termio/terminal/core"
+)
+
+// The mouse buttons.
+type MouseButton uint8
+
+const (
+	// No button, for the motion without a button held.
+	MouseButtonNone MouseButton = iota
+	MouseButtonLeft
+	MouseButtonMiddle
+	MouseButtonRight
+	MouseButtonWheelUp
+	MouseButtonWheelDown
+	MouseButtonWheelLeft
+	MouseButtonWheelRight
+)
+
+// What the mouse did.
+type MouseAction uint8
+
+const (
+	MouseActionPress MouseAction = iota
+	MouseActionRelease
+	MouseActionMotion
+)
+
+// A mouse event on the terminal.
+//
+// The embedder decides which motion events to send, usually one each time
+// the pointer enters a new cell. Motion events are only reported when the
+// program asked for them.
+type MouseEvent struct {
+	Action MouseAction
+
+	// The button pressed or released, for a motion the button held if any.
+	Button MouseButton
+
+	// The modifiers held, only shift, alt and ctrl are reported.
+	Mods Mods
+
+	// The cell under the pointer, 0-indexed from the top-left of the
+	// screen.
+	X, Y int
+
+	// The position of the pointer in pixels from the top-left of the
+	// screen, only used by the SGR pixels format (1016).
+	PixelX, PixelY int
+}
+
+// The largest coordinate the X10 format can encode in a byte, and the UTF-8
+// format in two bytes.
+const (
+	maxX10Coordinate  = 255 - 32
+	maxUTF8Coordinate = 2047 - 32
+)
+
+// EncodeMouse encodes the mouse event with the tracking mode and the format
+// the program set. Nil is returned if tracking is off, the tracking mode
+// doesn't report the event or the position can't be encoded.
+//
+// When tracking is off on the alternate screen in the alternate scroll mode
+// (1007), the wheel is sent as the up and down arrows so programs without
+// mouse support, e.g. less, can still be scrolled.
+//
+// See: https://invisible-island.net/xterm/ctlseqs/ctlseqs.html#h2-Mouse-Tracking
+func (e *Encoder) EncodeMouse(event MouseEvent) []byte {
+	if !e.reportsMouse(event) {
+		return e.alternateScroll(event)
+	}
+
+	sgr := e.Modes.Get(core.ModeMouseFormatSGR) || e.Modes.Get(core.ModeMouseFormatSGRPixels)
+	code := mouseButtonCode(event.Button)
+	if event.Action == MouseActionRelease && !sgr {
+		// Only the SGR formats say which button was released.
+		code = 3
+	}
+	if !e.Modes.Get(core.ModeMouseX10) {
+		if event.Mods&ModShift != 0 {
+			code += 4
+		}
+		if event.Mods&ModAlt != 0 {
+			code += 8
+		}
+		if event.Mods&ModCtrl != 0 {
+			code += 16
+		}
+	}
+	if event.Action == MouseActionMotion {
+		code += 32
+	}
+
+	// The coordinates are 1-indexed.
+	x, y := max(event.X, 0)+1, max(event.Y, 0)+1
+	final := byte('M')
+	switch {
+	case sgr:
+		// Releases keep the button and use m.
+		if event.Action == MouseActionRelease {
+			final = 'm'
+		}
+		if e.Modes.Get(core.ModeMouseFormatSGRPixels) {
+			x, y = max(event.PixelX, 0), max(event.PixelY, 0)
+		}
+		return fmt.Appendf(nil, "\x1b[<%d;%d;%d%c", code, x, y, final)
+
+	case e.Modes.Get(core.ModeMouseFormatURxvt):
+		return fmt.Appendf(nil, "\x1b[%d;%d;%dM", code+32, x, y)
+
+	case e.Modes.Get(core.ModeMouseFormatUTF8):
+		if x > maxUTF8Coordinate || y > maxUTF8Coordinate {
+			return nil
+		}
+		out := []byte{0x1b, '[', 'M', byte(code + 32)}
+		out = utf8.AppendRune(out, rune(x+32))
+		return utf8.AppendRune(out, rune(y+32))
+
+	default:
+		if x > maxX10Coordinate || y > maxX10Coordinate {
+			return nil
+		}
+		return []byte{0x1b, '[', 'M', byte(code + 32), byte(x + 32), byte(y + 32)}
+	}
+}
+
+// Returns true if the tracking mode set by the program reports the event.
+func (e *Encoder) reportsMouse(event MouseEvent) bool {
+	wheel := event.Button >= MouseButtonWheelUp
+	switch {
+	case event.Action == MouseActionRelease && wheel:
+		// The wheel has no releases.
+		return false
+	case e.Modes.Get(core.ModeMouseX10):
+		return event.Action == MouseActionPress && event.Button != MouseButtonNone
+	case e.Modes.Get(core.ModeMouseNormal):
+		return event.Action != MouseActionMotion
+	case e.Modes.Get(core.ModeMouseButton):
+		return event.Action != MouseActionMotion || event.Button != MouseButtonNone
+	case e.Modes.Get(core.ModeMouseAny):
+		return true
+	default:
+		return false
+	}
+}
+
+// Encode the wheel as an arrow key in the alternate scroll mode, nil is
+// returned for the other events or if the mode doesn't apply.
+func (e *Encoder) alternateScroll(event MouseEvent) []byte {
+	if !e.AltScreen || !e.Modes.Get(core.ModeAlternateScroll) || e.tracksMouse() {
+		return nil
+	}
+	if event.Action != MouseActionPress {
+		return nil
+	}
+	switch event.Button {
+	case MouseButtonWheelUp:
+		return e.cursorKey('A', 0)
+	case MouseButtonWheelDown:
+		return e.cursorKey('B', 0)
+	default:
+		return nil
+	}
+}
+
+// Returns true if the program set a mouse tracking mode.
+func (e *Encoder) tracksMouse() bool {
+	for _, mode := range core.MouseTrackingModes {
+		if e.Modes.Get(mode) {
+			return true
+		}
+	}
+	return false
+}
+
+// The button part of the event code, without the modifiers and motion.
+func mouseButtonCode(button MouseButton) int {
+	switch button {
+	case MouseButtonLeft:
+		return 0
+	case MouseButtonMiddle:
+		return 1
+	case MouseButtonRight:
+		return 2
+	case MouseButtonWheelUp:
+		return 64
+	case MouseButtonWheelDown:
+		return 65
+	case MouseButtonWheelLeft:
+		return 66
+	case MouseButtonWheelRight:
+		return 67
+	default:
+		return 3
+	}
+}
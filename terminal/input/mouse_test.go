@@ -0,0 +1,102 @@
+package input
+
+import (
+	"testing"
+
+	"github.com/hnimtadd/termio/terminal/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncoder_MouseTracking(t *testing.T) {
+	encoder := Encoder{Modes: core.NewModeState(core.ModePacked, core.ModePacked)}
+	press := MouseEvent{Action: MouseActionPress, Button: MouseButtonLeft, X: 1, Y: 2}
+	release := MouseEvent{Action: MouseActionRelease, Button: MouseButtonLeft, X: 1, Y: 2}
+	drag := MouseEvent{Action: MouseActionMotion, Button: MouseButtonLeft, X: 3, Y: 2}
+	motion := MouseEvent{Action: MouseActionMotion, X: 3, Y: 2}
+	wheel := MouseEvent{Action: MouseActionPress, Button: MouseButtonWheelDown, Mods: ModCtrl}
+
+	// Nothing is reported without tracking.
+	assert.Nil(t, encoder.EncodeMouse(press))
+
+	// X10 only reports presses, without modifiers.
+	encoder.Modes.Set(core.ModeMouseX10, true)
+	assert.Equal(t, "\x1b[M \"#", string(encoder.EncodeMouse(press)))
+	assert.Equal(t, "\x1b[M \"#", string(encoder.EncodeMouse(MouseEvent{Button: MouseButtonLeft, Mods: ModCtrl, X: 1, Y: 2})))
+	assert.Nil(t, encoder.EncodeMouse(release))
+
+	encoder.Modes.Set(core.ModeMouseX10, false)
+	encoder.Modes.Set(core.ModeMouseNormal, true)
+	assert.Equal(t, "\x1b[M \"#", string(encoder.EncodeMouse(press)))
+	assert.Equal(t, "\x1b[M#\"#", string(encoder.EncodeMouse(release)))
+	assert.Equal(t, "\x1b[Mq!!", string(encoder.EncodeMouse(wheel)))
+	assert.Nil(t, encoder.EncodeMouse(drag))
+	assert.Nil(t, encoder.EncodeMouse(MouseEvent{Action: MouseActionRelease, Button: MouseButtonWheelDown}))
+
+	encoder.Modes.Set(core.ModeMouseNormal, false)
+	encoder.Modes.Set(core.ModeMouseButton, true)
+	assert.Equal(t, "\x1b[M@$#", string(encoder.EncodeMouse(drag)))
+	assert.Nil(t, encoder.EncodeMouse(motion))
+
+	encoder.Modes.Set(core.ModeMouseButton, false)
+	encoder.Modes.Set(core.ModeMouseAny, true)
+	assert.Equal(t, "\x1b[MC$#", string(encoder.EncodeMouse(motion)))
+}
+
+func TestEncoder_MouseFormats(t *testing.T) {
+	encoder := Encoder{Modes: core.NewModeState(core.ModePacked, core.ModePacked)}
+	encoder.Modes.Set(core.ModeMouseNormal, true)
+	press := MouseEvent{Action: MouseActionPress, Button: MouseButtonRight, Mods: ModShift, X: 9, Y: 4, PixelX: 75, PixelY: 66}
+	release := MouseEvent{Action: MouseActionRelease, Button: MouseButtonRight, X: 9, Y: 4, PixelX: 75, PixelY: 66}
+	far := MouseEvent{Action: MouseActionPress, Button: MouseButtonLeft, X: 299, Y: 0}
+
+	// The default format can't encode columns past 223.
+	assert.Nil(t, encoder.EncodeMouse(far))
+
+	encoder.Modes.Set(core.ModeMouseFormatUTF8, true)
+	assert.Equal(t, "\x1b[M&*%", string(encoder.EncodeMouse(press)))
+	assert.Equal(t, "\x1b[M Ō!", string(encoder.EncodeMouse(far)))
+
+	encoder.Modes.Set(core.ModeMouseFormatUTF8, false)
+	encoder.Modes.Set(core.ModeMouseFormatSGR, true)
+	assert.Equal(t, "\x1b[<6;10;5M", string(encoder.EncodeMouse(press)))
+	assert.Equal(t, "\x1b[<2;10;5m", string(encoder.EncodeMouse(release)))
+	assert.Equal(t, "\x1b[<0;300;1M", string(encoder.EncodeMouse(far)))
+
+	encoder.Modes.Set(core.ModeMouseFormatSGR, false)
+	encoder.Modes.Set(core.ModeMouseFormatURxvt, true)
+	assert.Equal(t, "\x1b[38;10;5M", string(encoder.EncodeMouse(press)))
+	assert.Equal(t, "\x1b[35;10;5M", string(encoder.EncodeMouse(release)))
+
+	encoder.Modes.Set(core.ModeMouseFormatURxvt, false)
+	encoder.Modes.Set(core.ModeMouseFormatSGRPixels, true)
+	assert.Equal(t, "\x1b[<6;75;66M", string(encoder.EncodeMouse(press)))
+	assert.Equal(t, "\x1b[<2;75;66m", string(encoder.EncodeMouse(release)))
+}
+
+func TestEncoder_MouseAlternateScroll(t *testing.T) {
+	encoder := Encoder{Modes: core.NewModeState(core.ModePacked, core.ModePacked), AltScreen: true}
+	up := MouseEvent{Action: MouseActionPress, Button: MouseButtonWheelUp}
+	down := MouseEvent{Action: MouseActionPress, Button: MouseButtonWheelDown}
+
+	// The mode is off by default.
+	assert.Nil(t, encoder.EncodeMouse(up))
+
+	encoder.Modes.Set(core.ModeAlternateScroll, true)
+	assert.Equal(t, "\x1b[A", string(encoder.EncodeMouse(up)))
+	assert.Equal(t, "\x1b[B", string(encoder.EncodeMouse(down)))
+	assert.Nil(t, encoder.EncodeMouse(MouseEvent{Action: MouseActionPress, Button: MouseButtonLeft}))
+	assert.Nil(t, encoder.EncodeMouse(MouseEvent{Action: MouseActionPress, Button: MouseButtonWheelLeft}))
+
+	// The arrows follow the cursor keys mode.
+	encoder.Modes.Set(core.ModeCursorKeys, true)
+	assert.Equal(t, "\x1bOA", string(encoder.EncodeMouse(up)))
+
+	// Mouse tracking takes precedence.
+	encoder.Modes.Set(core.ModeMouseNormal, true)
+	assert.Equal(t, "\x1b[M`!!", string(encoder.EncodeMouse(up)))
+	encoder.Modes.Set(core.ModeMouseNormal, false)
+
+	// Only the alternate screen is scrolled with the arrows.
+	encoder.AltScreen = false
+	assert.Nil(t, encoder.EncodeMouse(up))
+}
@@ -0,0 +1,55 @@
+package input
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hnimtadd/termio/terminal/core"
+)
+
+// The markers around the pasted text in the bracketed paste mode (2004).
+const (
+	pasteStart = "\x1b[200~"
+	pasteEnd   = "\x1b[201~"
+)
+
+// EncodePaste encodes the text pasted by the user. In the bracketed paste
+// mode the text is wrapped in the paste markers so the program can tell it
+// apart from typed text, otherwise the newlines are sent as enter (CR).
+//
+// The pasted text can't be trusted: the paste markers and the control
+// characters other than tab, CR and LF are removed so a paste can't end the
+// bracketed paste early or send escape sequences, e.g. to run the rest of
+// the paste as typed commands.
+func (e *Encoder) EncodePaste(text string) []byte {
+	text = sanitizePaste(text)
+	if e.Modes.Get(core.ModeBracketedPaste) {
+		out := make([]byte, 0, len(pasteStart)+len(text)+len(pasteEnd))
+		out = append(out, pasteStart...)
+		out = append(out, text...)
+		return append(out, pasteEnd...)
+	}
+	text = strings.ReplaceAll(text, "\r\n", "\r")
+	return []byte(strings.ReplaceAll(text, "\n", "\r"))
+}
+
+// Remove the paste markers, the C0 and C1 control characters except tab, CR
+// and LF, DEL and the invalid UTF-8 bytes.
+func sanitizePaste(text string) string {
+	text = strings.ReplaceAll(text, pasteStart, "")
+	text = strings.ReplaceAll(text, pasteEnd, "")
+
+	var b strings.Builder
+	b.Grow(len(text))
+	for len(text) > 0 {
+		r, size := utf8.DecodeRuneInString(text)
+		safe := r >= 0x20 && r != 0x7F && (r < 0x80 || r > 0x9F)
+		switch {
+		case r == utf8.RuneError && size == 1:
+		case r == '\t' || r == '\r' || r == '\n' || safe:
+			b.WriteString(text[:size])
+		}
+		text = text[size:]
+	}
+	return b.String()
+}
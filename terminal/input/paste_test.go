@@ -0,0 +1,41 @@
+package input
+
+import (
+	"testing"
+
+	"github.com/hnimtadd/termio/terminal/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncoder_Paste(t *testing.T) {
+	encoder := Encoder{Modes: core.NewModeState(core.ModePacked, core.ModePacked)}
+
+	// Newlines are sent as enter.
+	assert.Equal(t, "ls\rpwd\r", string(encoder.EncodePaste("ls\npwd\r\n")))
+	assert.Equal(t, "a\tb", string(encoder.EncodePaste("a\tb")))
+
+	encoder.Modes.Set(core.ModeBracketedPaste, true)
+	assert.Equal(t, "\x1b[200~ls\npwd\r\n\x1b[201~", string(encoder.EncodePaste("ls\npwd\r\n")))
+	assert.Equal(t, "\x1b[200~\x1b[201~", string(encoder.EncodePaste("")))
+}
+
+func TestEncoder_PasteSanitize(t *testing.T) {
+	encoder := Encoder{Modes: core.NewModeState(core.ModePacked, core.ModePacked)}
+	encoder.Modes.Set(core.ModeBracketedPaste, true)
+
+	// The paste can't end the bracketed paste and run a command.
+	assert.Equal(t,
+		"\x1b[200~echo hi\nrm -rf ~\n\x1b[201~",
+		string(encoder.EncodePaste("echo hi\x1b[201~\nrm -rf ~\n")),
+	)
+
+	// Control characters, C1 controls and invalid UTF-8 are removed,
+	// other text is kept.
+	assert.Equal(t,
+		"\x1b[200~[31mredé橋\x1b[201~",
+		string(encoder.EncodePaste("\x1b[31mred\x00\x03\x7f\u009b\xffé橋")),
+	)
+
+	encoder.Modes.Set(core.ModeBracketedPaste, false)
+	assert.Equal(t, "a[200~b\r", string(encoder.EncodePaste("a\x1b\x1b[200~[200~b\x08\n")))
+}
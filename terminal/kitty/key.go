@@ -0,0 +1,75 @@
+// Package kitty implements the state of the kitty keyboard protocol, the
+// progressive enhancements of the key encoding a program can ask for.
+//
+// See: https://sw.kovidgoyal.net/kitty/keyboard-protocol/
+package kitty
+
+// The progressive enhancement flags.
+type KeyFlags uint8
+
+const (
+	KeyFlagDisambiguate    KeyFlags = 1 << iota // Disambiguate escape codes
+	KeyFlagReportEvents                         // Report the repeat and release events
+	KeyFlagReportAlternate                      // Report the shifted key
+	KeyFlagReportAll                            // Report all keys as escape codes
+	KeyFlagReportText                           // Report the text of the key
+
+	KeyFlagsNone KeyFlags = 0
+	KeyFlagsAll  KeyFlags = KeyFlagDisambiguate | KeyFlagReportEvents |
+		KeyFlagReportAlternate | KeyFlagReportAll | KeyFlagReportText
+)
+
+// How CSI = flags ; mode u changes the current flags.
+type KeySetMode uint8
+
+const (
+	KeySetModeSet KeySetMode = 1 // Replace the flags
+	KeySetModeOr  KeySetMode = 2 // Set the given bits
+	KeySetModeNot KeySetMode = 3 // Reset the given bits
+)
+
+// The number of entries of the stack, pushing more drops the oldest entry.
+const keyFlagStackLen = 8
+
+// The stack of flags programs push and pop, each screen has its own. The
+// top of the stack is the current flags.
+type KeyFlagStack struct {
+	flags [keyFlagStackLen]KeyFlags
+	idx   int
+}
+
+// Return the current flags.
+func (s *KeyFlagStack) Current() KeyFlags {
+	return s.flags[s.idx]
+}
+
+// Push the flags, they become the current flags (CSI > flags u).
+func (s *KeyFlagStack) Push(flags KeyFlags) {
+	s.idx = (s.idx + 1) % keyFlagStackLen
+	s.flags[s.idx] = flags & KeyFlagsAll
+}
+
+// Pop n entries (CSI < n u), popping every entry resets all the flags.
+func (s *KeyFlagStack) Pop(n int) {
+	if n >= keyFlagStackLen {
+		*s = KeyFlagStack{}
+		return
+	}
+	for range n {
+		s.flags[s.idx] = KeyFlagsNone
+		s.idx = (s.idx + keyFlagStackLen - 1) % keyFlagStackLen
+	}
+}
+
+// Change the current flags (CSI = flags ; mode u).
+func (s *KeyFlagStack) Set(mode KeySetMode, flags KeyFlags) {
+	flags &= KeyFlagsAll
+	switch mode {
+	case KeySetModeSet:
+		s.flags[s.idx] = flags
+	case KeySetModeOr:
+		s.flags[s.idx] |= flags
+	case KeySetModeNot:
+		s.flags[s.idx] &^= flags
+	}
+}
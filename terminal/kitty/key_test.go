@@ -0,0 +1,47 @@
+package kitty
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyFlagStack(t *testing.T) {
+	var stack KeyFlagStack
+	assert.Equal(t, KeyFlagsNone, stack.Current())
+
+	stack.Push(KeyFlagDisambiguate)
+	stack.Push(KeyFlagDisambiguate | KeyFlagReportAll)
+	assert.Equal(t, KeyFlagDisambiguate|KeyFlagReportAll, stack.Current())
+
+	stack.Set(KeySetModeNot, KeyFlagDisambiguate)
+	assert.Equal(t, KeyFlagReportAll, stack.Current())
+	stack.Set(KeySetModeOr, KeyFlagReportEvents)
+	assert.Equal(t, KeyFlagReportAll|KeyFlagReportEvents, stack.Current())
+	stack.Set(KeySetModeSet, KeyFlagReportText)
+	assert.Equal(t, KeyFlagReportText, stack.Current())
+
+	stack.Pop(1)
+	assert.Equal(t, KeyFlagDisambiguate, stack.Current())
+	stack.Pop(1)
+	assert.Equal(t, KeyFlagsNone, stack.Current())
+
+	// Popping more than was pushed resets the flags.
+	stack.Push(KeyFlagDisambiguate)
+	stack.Pop(5)
+	assert.Equal(t, KeyFlagsNone, stack.Current())
+}
+
+func TestKeyFlagStackOverflow(t *testing.T) {
+	var stack KeyFlagStack
+	for i := range 10 {
+		stack.Push(KeyFlags(i + 1))
+	}
+	assert.Equal(t, KeyFlags(10), stack.Current())
+
+	// The oldest entries were dropped, only 8 are kept.
+	stack.Pop(7)
+	assert.Equal(t, KeyFlags(3), stack.Current())
+	stack.Pop(1)
+	assert.Equal(t, KeyFlagsNone, stack.Current())
+}
@@ -32,6 +32,10 @@ type Cell struct {
 	// The style ID to use for this cell within the style map. Zero
 	// is always the default style so no lookup is required.
 	StyleID styleid.ID
+
+	// The hyperlink ID of this cell within the hyperlink set of the page.
+	// Zero means the cell is not part of a hyperlink.
+	HyperlinkID HyperlinkID
 }
 
 func (c *Cell) Codepoint() uint32 {
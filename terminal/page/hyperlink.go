@@ -0,0 +1,63 @@
+package page
+
+import (
+	"hash/fnv"
+	"strconv"
+
+	"github.com/hnimtadd/termio/terminal/set"
+)
+
+// The ID of a hyperlink within the hyperlink set of a page. Zero is reserved
+// for cells that are not part of a hyperlink.
+type HyperlinkID uint64
+
+// A hyperlink as set by OSC 8. Cells of the same link share the same entry in
+// the hyperlink set of their page.
+//
+// See: https://gist.github.com/egmontkob/eb114294efbcd5adb1944c9f3cb5feda
+type Hyperlink struct {
+	// The id= parameter given by the program. Cells with the same explicit
+	// ID and URI belong to the same link even if they aren't contiguous,
+	// e.g. a link spanning multiple lines of a TUI.
+	ExplicitID string
+
+	// Links opened without an explicit ID get a unique number from the
+	// screen so that two separate links to the same URI are not merged.
+	ImplicitID uint64
+
+	URI string
+}
+
+// Hash implements set.Hashable.
+func (h Hyperlink) Hash() uint64 {
+	hasher := fnv.New64a()
+	hasher.Write([]byte(h.ExplicitID))
+	hasher.Write([]byte{0})
+	hasher.Write([]byte(strconv.FormatUint(h.ImplicitID, 10)))
+	hasher.Write([]byte{0})
+	hasher.Write([]byte(h.URI))
+	return hasher.Sum64()
+}
+
+// Equals implements set.Hashable.
+func (h Hyperlink) Equals(other set.Hashable) bool {
+	o, ok := other.(Hyperlink)
+	return ok && h == o
+}
+
+// Delete implements set.Hashable.
+func (h Hyperlink) Delete() {}
+
+// Returns the hyperlink with the given ID, or nil if the ID is not in use
+// on this page.
+func (p *Page) LookupHyperlink(id HyperlinkID) *Hyperlink {
+	if id == 0 {
+		return nil
+	}
+	item := p.Hyperlinks.Get(set.ID(id))
+	if item == nil {
+		return nil
+	}
+	link := item.(Hyperlink)
+	return &link
+}
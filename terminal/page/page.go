@@ -47,6 +47,10 @@ type Page struct {
 	// The availabes set of styles in use on this page.
 	Styles *set.RefCountedSet
 
+	// The set of hyperlinks (OSC 8) in use on this page, cells reference
+	// them by their HyperlinkID.
+	Hyperlinks *set.RefCountedSet
+
 	// Dirty bits in the page.
 	// Each bit represents a row in the page, and if the bit is set,
 	// then the row is dirty and requires a redraw. Dirty status is only ever
@@ -92,6 +96,9 @@ func InitPage(cap Capacity) *Page {
 		Styles: set.NewRefCountedSet(set.Options{
 			Cap: utils.PointerTo(uint64(cap.Styles)),
 		}),
+		Hyperlinks: set.NewRefCountedSet(set.Options{
+			Cap: utils.PointerTo(uint64(cap.Hyperlinks)),
+		}),
 		Size:     Size{Cols: cap.Cols, Rows: cap.Rows},
 		Capacity: cap,
 		Dirty:    utils.NewStaticBitSet(int(cap.Rows)),
@@ -128,6 +135,7 @@ func (p *Page) MoveCells(
 		srcCell.ContentTag = ContentTagCP
 		srcCell.ContentCP = 0
 		srcCell.StyleID = 0
+		srcCell.HyperlinkID = 0
 		srcCell.Wide = WideNarrow
 		srcCell.IsDirty = true
 		
@@ -171,6 +179,10 @@ func (p *Page) ClonePartialRowFrom(
 			// For now, reset to default style to avoid dangling references
 			dstCell.StyleID = 0
 		}
+		if srcPage != p && srcCell.HyperlinkID != 0 {
+			// Same as styles, hyperlink IDs are page specific.
+			dstCell.HyperlinkID = 0
+		}
 	}
 	
 	return nil
@@ -217,6 +229,9 @@ type Capacity struct {
 
 	// Number of unique Styles that can be used on this page.
 	Styles uint
+
+	// Number of unique Hyperlinks that can be used on this page.
+	Hyperlinks uint
 }
 
 func (c Capacity) Size() uint64 {
@@ -264,9 +279,10 @@ func (c *Capacity) Adjust(req Adjustment) error {
 // pages of standard capacity use a pooled allocator instead of single-use
 // mmaps.
 var StandardCapacity = Capacity{
-	Cols:   215,
-	Rows:   215,
-	Styles: 128,
+	Cols:       215,
+	Rows:       215,
+	Styles:     128,
+	Hyperlinks: 32,
 }
 
 type EncodeUtf8Options struct {
@@ -539,8 +555,21 @@ func (p *Page) IsRowDirty(y size.CellCountInt) bool {
 	return p.Dirty.IsSet(int(y))
 }
 
-func (p *Page) CloneRowFrom(data *Page, row *Row, param3 *Row) {
-	// TODO: Implement this function.
+// Clone a full row of the source page into the destination row of this page,
+// including the row metadata. See ClonePartialRowFrom for the caveats of
+// cloning from another page.
+func (p *Page) CloneRowFrom(srcPage *Page, dstRow *Row, srcRow *Row) {
+	cols := min(len(dstRow.Cells), len(srcRow.Cells))
+	if cols == 0 {
+		return
+	}
+	_ = p.ClonePartialRowFrom(srcPage, dstRow, srcRow, 0, size.CellCountInt(cols))
+
+	dstRow.Wrap = srcRow.Wrap
+	dstRow.WrapContinuation = srcRow.WrapContinuation
+	dstRow.Styled = srcRow.Styled
+	dstRow.Hyperlink = srcRow.Hyperlink
+	dstRow.SemanticPrompt = srcRow.SemanticPrompt
 }
 
 // Clear the cells in the given row.
@@ -558,6 +587,17 @@ func (p *Page) ClearCells(row *Row, left int, end size.CellCountInt) {
 			row.Styled = false
 		}
 	}
+	if row.Hyperlink {
+		for _, cell := range cells {
+			if cell.HyperlinkID == 0 {
+				continue
+			}
+			p.Hyperlinks.Release(set.ID(cell.HyperlinkID))
+		}
+		if len(cells) == int(p.Size.Cols) {
+			row.Hyperlink = false
+		}
+	}
 
 	// Zero out the cells in the row.
 	for _, cell := range cells {
@@ -23,6 +23,10 @@ type Row struct {
 	// At the time of writing this, the speed difference is around 4x.
 	Styled bool
 
+	// True if any of the cells in this row are part of a hyperlink. Like
+	// Styled, this can have false positives but never a false negative.
+	Hyperlink bool
+
 	// The semantic prompt type for this row as specified by the running
 	// program, or "unknow" if it was never set.
 	SemanticPrompt SemanticPromptType
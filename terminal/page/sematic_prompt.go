@@ -8,11 +8,11 @@ package page
 type SemanticPromptType int
 
 const (
-	SemanticPromptTypePrompt SemanticPromptType = iota
+	SemanticPromptTypeUnknow SemanticPromptType = iota
+	SemanticPromptTypePrompt
 	SemanticPromptTypeContinuation
 	SemanticPromptTypeInput
 	SemanticPromptTypeOutput
-	SemanticPromptTypeUnknow
 )
 
 // Return trues if this is a prompt or input line type.
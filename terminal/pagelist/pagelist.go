@@ -75,7 +75,7 @@ func (p *PageList) EraseRow(pt point.Point) {
 	// the removed row.
 	{
 		for p := range p.TrackedPins.All() {
-			if p.Node == node && p.Y >= pin.Y {
+			if p.Node == node && p.Y > pin.Y {
 				p.Y -= 1
 			}
 		}
@@ -88,8 +88,8 @@ func (p *PageList) EraseRow(pt point.Point) {
 
 	// We iterate through all of the following pages in order to move their
 	// rows up by 1 as well
-	for next := node.Next; next != nil; {
-		nextRows := node.Next.Data.Rows
+	for next := node.Next; next != nil; next = node.Next {
+		nextRows := next.Data.Rows
 
 		node.Data.CloneRowFrom(
 			next.Data,
@@ -236,7 +236,7 @@ func (p *PageList) EraseRowsBounded(pt point.Point, limit size.CellCountInt) {
 	// our pin.
 	if node.Data.Size.Rows-pin.Y > limit {
 		node.Data.ClearCells(rows[pin.Y], 0, node.Data.Size.Cols)
-		utils.RotateOnce(rows[pin.Y : limit+1])
+		utils.RotateOnce(rows[pin.Y : pin.Y+limit+1])
 
 		// Set all the rows as dirty
 		dirty := node.Data.DirtyBitSet()
@@ -247,7 +247,7 @@ func (p *PageList) EraseRowsBounded(pt point.Point, limit size.CellCountInt) {
 		for p := range p.TrackedPins.All() {
 			if p.Node == node &&
 				p.Y >= pin.Y &&
-				p.Y < pin.Y+limit {
+				p.Y <= pin.Y+limit {
 				if p.Y == 0 {
 					p.X = 0
 				} else {
@@ -284,31 +284,31 @@ func (p *PageList) EraseRowsBounded(pt point.Point, limit size.CellCountInt) {
 		}
 	}
 
-	for next := node.Next; next != nil; {
-		nextRows := next.Data.Rows
-		node.Data.CloneRowFrom(
-			next.Data,
-			rows[node.Data.Size.Rows-1],
-			nextRows[0],
+	for next := node.Next; next != nil; next = next.Next {
+		prev, prevRows := node, rows
+		node, rows = next, next.Data.Rows
+
+		// Shift the top row of this page to the bottom of the previous page.
+		prev.Data.CloneRowFrom(
+			node.Data,
+			prevRows[prev.Data.Size.Rows-1],
+			rows[0],
 		)
-		node = node.Next
-		rows = nextRows
 
 		// We check to see if this page contains enough rows to sastify the
 		// specified limit, accounting for rows we've already shifted in previous
 		// pages.
 		//
 		// After this, the logic is similar to the one before the loop.
-
 		limit := limit - shifted
 
-		if node.Data.Size.Rows-pin.Y > limit {
-			node.Data.ClearCells(rows[pin.Y], 0, node.Data.Size.Cols)
-			utils.RotateOnce(rows[pin.Y : limit+1])
+		if node.Data.Size.Rows > limit {
+			node.Data.ClearCells(rows[0], 0, node.Data.Size.Cols)
+			utils.RotateOnce(rows[0 : limit+1])
 
 			// Set all the rows as dirty
 			dirty := node.Data.DirtyBitSet()
-			dirty.SetRange(int(pin.Y), int(pin.Y+limit))
+			dirty.SetRange(0, int(limit))
 
 			// Update pins in the shifted region.
 			for p := range p.TrackedPins.All() {
@@ -316,8 +316,8 @@ func (p *PageList) EraseRowsBounded(pt point.Point, limit size.CellCountInt) {
 					continue
 				}
 				if p.Y == 0 {
-					p.Node = node.Prev
-					p.Y = node.Prev.Data.Size.Rows - 1
+					p.Node = prev
+					p.Y = prev.Data.Size.Rows - 1
 					continue
 				}
 				p.Y -= 1
@@ -325,23 +325,24 @@ func (p *PageList) EraseRowsBounded(pt point.Point, limit size.CellCountInt) {
 			return
 		}
 
-		utils.RotateOnce(rows[pin.Y:node.Data.Size.Rows])
+		utils.RotateOnce(rows[0:node.Data.Size.Rows])
 
 		// Set all the rows as dirty.
 		dirty := node.Data.DirtyBitSet()
-		dirty.SetRange(int(pin.Y), int(node.Data.Size.Rows))
-		shifted = node.Data.Size.Rows
+		dirty.SetRange(0, int(node.Data.Size.Rows))
+		shifted += node.Data.Size.Rows
 
 		// Update tracked pins on current page.
-
 		for p := range p.TrackedPins.All() {
-			if p.Node == node && p.Y >= pin.Y {
-				if p.Y == 0 {
-					p.X = 0
-				} else {
-					p.Y -= 1
-				}
+			if p.Node != node {
+				continue
+			}
+			if p.Y == 0 {
+				p.Node = prev
+				p.Y = prev.Data.Size.Rows - 1
+				continue
 			}
+			p.Y -= 1
 		}
 	}
 
@@ -360,13 +361,19 @@ func (p *PageList) ClearDirty() {
 	}
 }
 
+// Reset the page list to a blank active area, the scrollback is dropped.
+// Tracked pins are kept and moved to the top-left of the active area.
 func (p *PageList) Reset() {
-	// For now, implement a basic reset that doesn't call undefined methods
-	// This is a placeholder that maintains compatibility
-	if p.Pages != nil {
-		// Clear dirty flags if the method exists
-		// Note: We can't call undefined methods, so this is minimal implementation
-		p.ViewPortPin = nil
+	p.Pages = p.InitPages(p.Cols, p.Rows)
+	p.PageSize = 0
+
+	p.ViewPort = ViewportTagActive
+	p.ViewPortPin.Node = p.Pages.First
+	p.ViewPortPin.X, p.ViewPortPin.Y = 0, 0
+
+	for pin := range p.TrackedPins.All() {
+		pin.Node = p.Pages.First
+		pin.X, pin.Y = 0, 0
 	}
 }
 
@@ -475,6 +482,23 @@ func (p *PageList) EncodeUtf8(w io.Writer, opts EncodeUtf8Options) (int64, error
 	return writen, nil
 }
 
+// Return an iterator over the rows from tl down to bl, inclusive, grouped by
+// page. If bl is nil, the rows are iterated to the bottom-right of the area
+// of tl.
+func (p *PageList) PageIterator(tl point.Point, bl *point.Point) *PageIterator {
+	topLeft := p.Pin(tl)
+	if topLeft == nil {
+		return &PageIterator{}
+	}
+	var bottomRight *Pin
+	if bl != nil {
+		bottomRight = p.Pin(*bl)
+	} else {
+		bottomRight = p.GetBottomRight(tl.Tag)
+	}
+	return topLeft.PageIterator(directionRightDown, bottomRight)
+}
+
 // Get the top-left of the screen for the given tag.
 func (p *PageList) GetTopLeft(tag point.Tag) *Pin {
 	switch tag {
@@ -496,7 +520,7 @@ func (p *PageList) GetTopLeft(tag point.Tag) *Pin {
 	// load, this makes a measureable difference.
 	case point.TagActive:
 		rem := p.Rows
-		it := p.Pages.First
+		it := p.Pages.Last
 		for ; it != nil; it = it.Prev {
 			if rem <= it.Data.Size.Rows {
 				return &Pin{
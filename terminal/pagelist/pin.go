@@ -79,6 +79,9 @@ func (p *Pin) Up(n size.CellCountInt) *Pin {
 
 	// Need to traverse page links to find the page.
 	node := p.Node.Prev
+	if node == nil {
+		return nil // No more pages to traverse.
+	}
 	rem := n - p.Y
 	for rem > node.Data.Size.Rows {
 		rem -= node.Data.Size.Rows
@@ -112,6 +115,9 @@ func (p *Pin) Down(n size.CellCountInt) *Pin {
 
 	// Need to traverse page links to find the page.
 	node := p.Node.Next
+	if node == nil {
+		return nil // No more pages to traverse.
+	}
 	rem := n - availRows
 	for rem > node.Data.Size.Rows {
 		rem -= node.Data.Size.Rows
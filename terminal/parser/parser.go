@@ -49,6 +49,7 @@ func NewParser() *Parser {
 		paramAccIdx:      0,
 		table:            newParserTable(),
 		paramsSet:        utils.NewStaticBitSet(MaxParams),
+		oscParser:        osc.NewParser(),
 	}
 }
 
@@ -91,8 +92,8 @@ func (p *Parser) Next(c uint8) [3]*Action {
 		if p.State != nextState {
 			switch p.State {
 			case StateOSCString:
-				// oscEnd
-				if cmd := p.oscParser.End(); cmd != nil {
+				// oscEnd, c is the terminator (BEL, ESC or ST)
+				if cmd := p.oscParser.End(c); cmd != nil {
 					exitAction = &Action{
 						Type:            ActionOSCEnd,
 						OSCDispatchData: cmd,
@@ -3,7 +3,9 @@ package parser
 import (
 	"testing"
 
+	"github.com/hnimtadd/termio/terminal/sequences/osc"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestParserNext(t *testing.T) {
@@ -56,3 +58,56 @@ func TestParserNext(t *testing.T) {
 		})
 	}
 }
+
+func TestParserOSC(t *testing.T) {
+	tcs := []struct {
+		name       string
+		input      []uint8
+		terminator osc.Terminator
+		title      string
+	}{
+		{
+			name:       "BEL terminated",
+			input:      []uint8("\x1b]2;hello\x07"),
+			terminator: osc.TerminatorBEL,
+			title:      "hello",
+		},
+		{
+			name:       "ST terminated",
+			input:      []uint8("\x1b]2;hello\x1b"),
+			terminator: osc.TerminatorST,
+			title:      "hello",
+		},
+		{
+			name:       "UTF-8 payload",
+			input:      []uint8("\x1b]2;h\u00e9llo \u2603\x07"),
+			terminator: osc.TerminatorBEL,
+			title:      "h\u00e9llo \u2603",
+		},
+		{
+			// U+271C is E2 9C 9C in UTF-8, 0x9C isn't the 8-bit ST here.
+			name:       "0x9C in the payload",
+			input:      []uint8("\x1b]2;\u271c \x9c!\x07"),
+			terminator: osc.TerminatorBEL,
+			title:      "\u271c \x9c!",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			p := NewParser()
+			for _, c := range tc.input[:len(tc.input)-1] {
+				p.Next(c)
+			}
+			actions := p.Next(tc.input[len(tc.input)-1])
+			require.NotNil(t, actions[0])
+			assert.Equal(t, ActionOSCEnd, actions[0].Type)
+
+			cmd := actions[0].OSCDispatchData
+			require.NotNil(t, cmd)
+			assert.Equal(t, osc.CommandTypeChangeWindowTitle, cmd.Type)
+			assert.Equal(t, tc.terminator, cmd.Terminator)
+			assert.Equal(t, tc.title, cmd.WindowTitleData.Title)
+		})
+	}
+}
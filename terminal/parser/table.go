@@ -12,7 +12,7 @@ func newParserTable() parserTable {
 	var t parserTable = make(map[uint8]map[State]Transition)
 
 	// init table
-	for ch := range math.MaxUint8 {
+	for ch := range math.MaxUint8 + 1 {
 		t[uint8(ch)] = make(map[State]Transition)
 	}
 
@@ -232,14 +232,21 @@ func newParserTable() parserTable {
 	{
 		source := StateOSCString
 
-		// ground
-		t.addSingle(0x9C, source, StateGround, ActionNone)
+		// ground, BEL is the xterm-specific terminator, ESC \ is handled
+		// by the anywhere => escape transition
+		t.addSingle(0x07, source, StateGround, ActionNone)
 
 		// internal events
-		t.addRange(0x00, 0x17, source, source, ActionIgnore)
+		t.addRange(0x00, 0x06, source, source, ActionIgnore)
+		t.addRange(0x08, 0x17, source, source, ActionIgnore)
 		t.addSingle(0x19, source, source, ActionIgnore)
 		t.addRange(0x1C, 0x1F, source, source, ActionIgnore)
-		t.addRange(0x20, 0x7F, source, source, ActionOSCPut)
+
+		// Overrides the anywhere transitions so the UTF-8 payloads
+		// (e.g. window titles) are passed through to the OSC parser. This
+		// includes 0x9C, a UTF-8 continuation byte, so the 8-bit ST doesn't
+		// end an OSC.
+		t.addRange(0x20, 0xFF, source, source, ActionOSCPut)
 	}
 
 	// dcsParam
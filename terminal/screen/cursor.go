@@ -1,6 +1,7 @@
 package screen
 
 import (
+	"github.com/hnimtadd/termio/terminal/charsets"
 	"github.com/hnimtadd/termio/terminal/page"
 	"github.com/hnimtadd/termio/terminal/pagelist"
 	"github.com/hnimtadd/termio/terminal/size"
@@ -26,4 +27,48 @@ type Cursor struct {
 	// we change pages, we need to ensurethat update that page with our style
 	// when used.
 	StyleID styleid.ID
+
+	// The hyperlink (OSC 8) that printed cells become part of, nil if there
+	// is none. Like the style, the ID is page-specific.
+	Hyperlink   *page.Hyperlink
+	HyperlinkID page.HyperlinkID
+
+	// How the cursor is drawn, set by the program with DECSCUSR and DECTCEM
+	// (mode 25).
+	Shape    CursorShape
+	Blinking bool
+	Visible  bool
+}
+
+// The shape of the cursor.
+type CursorShape uint8
+
+const (
+	CursorShapeBlock CursorShape = iota
+	CursorShapeUnderline
+	CursorShapeBar
+)
+
+func (s CursorShape) String() string {
+	switch s {
+	case CursorShapeBlock:
+		return "block"
+	case CursorShapeUnderline:
+		return "underline"
+	case CursorShapeBar:
+		return "bar"
+	default:
+		return "unknown"
+	}
+}
+
+// The cursor state saved with DECSC (ESC 7), and when switching to the
+// alternate screen with mode 1049, so it can be restored later. Each screen
+// has its own saved cursor.
+type SavedCursor struct {
+	X, Y        size.CellCountInt
+	PendingWrap bool
+	Style       style.Style
+	Origin      bool // Whether the origin mode (DECOM) was set
+	Charset     charsets.State
 }
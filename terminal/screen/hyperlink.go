@@ -0,0 +1,123 @@
+package screen
+
+import (
+	"github.com/hnimtadd/termio/terminal/coordinate"
+	pagepkg "github.com/hnimtadd/termio/terminal/page"
+	"github.com/hnimtadd/termio/terminal/pagelist"
+	"github.com/hnimtadd/termio/terminal/point"
+	"github.com/hnimtadd/termio/terminal/size"
+)
+
+// A hyperlink on the screen and the cells it covers.
+type HyperlinkRange struct {
+	URI string
+
+	// The explicit id= of the link, empty if the link didn't have one.
+	ID string
+
+	// The first and the last cell (inclusive) of the link, in the same
+	// coordinate space as the point the link was looked up with. The range
+	// is limited to the area of that coordinate space.
+	Start, End point.Point
+}
+
+// Return the hyperlink under the given point with the full range of cells it
+// covers, or nil if there is no hyperlink there.
+//
+// A link spanning multiple rows, either because it was wrapped or because
+// the program printed it with the same id= on every row, is followed as long
+// as every row in between contains a part of the link.
+func (s *Screen) HyperlinkAt(pt point.Point) *HyperlinkRange {
+	pin := s.Pages.Pin(pt)
+	if pin == nil {
+		return nil
+	}
+	link := hyperlinkAt(*pin)
+	if link == nil {
+		return nil
+	}
+
+	// The active area and the viewport end at the last row of the screen,
+	// the other areas are only bounded by the written rows.
+	bounded := pt.Tag == point.TagActive || pt.Tag == point.TagViewPort
+
+	start, startY := *pin, pt.Coordinate.Y
+	for startY > 0 {
+		up := start.Up(1)
+		if up == nil || !rowHasHyperlink(*up, *link) {
+			break
+		}
+		start, startY = *up, startY-1
+	}
+
+	end, endY := *pin, pt.Coordinate.Y
+	for !bounded || endY < s.rows-1 {
+		down := end.Down(1)
+		if down == nil || !rowHasHyperlink(*down, *link) {
+			break
+		}
+		end, endY = *down, endY+1
+	}
+
+	// Narrow the range to the first and the last cell of the link.
+	startX, endX := pt.Coordinate.X, pt.Coordinate.X
+	for x := range start.Node.Data.Size.Cols {
+		start.X = x
+		if hyperlinkEqual(hyperlinkAt(start), link) {
+			startX = x
+			break
+		}
+	}
+	for x := end.Node.Data.Size.Cols; x > 0; x-- {
+		end.X = x - 1
+		if hyperlinkEqual(hyperlinkAt(end), link) {
+			endX = x - 1
+			break
+		}
+	}
+
+	return &HyperlinkRange{
+		URI: link.URI,
+		ID:  link.ExplicitID,
+		Start: point.Point{
+			Tag:        pt.Tag,
+			Coordinate: coordinate.Point[size.CellCountInt]{X: startX, Y: startY},
+		},
+		End: point.Point{
+			Tag:        pt.Tag,
+			Coordinate: coordinate.Point[size.CellCountInt]{X: endX, Y: endY},
+		},
+	}
+}
+
+// Return the hyperlink of the cell at the pin, or nil if it has none.
+func hyperlinkAt(pin pagelist.Pin) *pagepkg.Hyperlink {
+	cell := pin.RowAndCell().Cell
+	if cell.HyperlinkID == 0 {
+		return nil
+	}
+	return pin.Node.Data.LookupHyperlink(cell.HyperlinkID)
+}
+
+// Returns true if any cell in the row of the pin is part of the link.
+func rowHasHyperlink(pin pagelist.Pin, link pagepkg.Hyperlink) bool {
+	page := pin.Node.Data
+	row := page.GetRow(pin.Y)
+	if !row.Hyperlink {
+		return false
+	}
+	for _, cell := range page.GetCells(row) {
+		if cell.HyperlinkID == 0 {
+			continue
+		}
+		if hyperlinkEqual(page.LookupHyperlink(cell.HyperlinkID), &link) {
+			return true
+		}
+	}
+	return false
+}
+
+// Hyperlink IDs are page specific so links are compared by value.
+func hyperlinkEqual(a, b *pagepkg.Hyperlink) bool {
+	return a != nil && b != nil && *a == *b
+}
@@ -3,13 +3,13 @@
 package screenmock
 
 import (
-	io "github.com/hnimtadd/termio/io"
 	page "github.com/hnimtadd/termio/terminal/page"
 	point "github.com/hnimtadd/termio/terminal/point"
 	screen "github.com/hnimtadd/termio/terminal/screen"
 	sgr "github.com/hnimtadd/termio/terminal/sgr"
 	size "github.com/hnimtadd/termio/terminal/size"
 	mock "github.com/stretchr/testify/mock"
+	io "io"
 )
 
 // MockScreen is an autogenerated mock type for the ScreenInt type
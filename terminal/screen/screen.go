@@ -5,8 +5,11 @@ package screen
 import (
 	"fmt"
 	"io"
+	"strings"
 
+	"github.com/hnimtadd/termio/terminal/charsets"
 	"github.com/hnimtadd/termio/terminal/color"
+	"github.com/hnimtadd/termio/terminal/kitty"
 	pagepkg "github.com/hnimtadd/termio/terminal/page"
 	"github.com/hnimtadd/termio/terminal/pagelist"
 	"github.com/hnimtadd/termio/terminal/point"
@@ -103,6 +106,19 @@ type Screen struct {
 	//  this because MaxSize 0 in PageLists gets rounded up to two pages so we
 	//  can alwasy have an active screen..
 	NoScrollback bool
+
+	// The cursor saved by DECSC, nil if nothing was saved.
+	SavedCursor *SavedCursor
+
+	// The character sets designated and invoked by the program.
+	Charset charsets.State
+
+	// The kitty keyboard protocol flags pushed by the program, each screen
+	// has its own stack.
+	KittyKeyboard kitty.KeyFlagStack
+
+	// The last implicit ID given to a hyperlink opened without an id=.
+	hyperlinkImplicitID uint64
 }
 
 // Initialize a new display
@@ -121,10 +137,13 @@ func NewScreen(cols, rows size.CellCountInt) *Screen {
 			PageRow:  pageRAC.Row,
 			PageCell: pageRAC.Cell,
 			PagePin:  pagePin,
+			Blinking: true,
+			Visible:  true,
 		},
-		Pages: pages,
-		rows:  rows,
-		cols:  cols,
+		Pages:   pages,
+		Charset: charsets.NewState(),
+		rows:    rows,
+		cols:    cols,
 	}
 }
 
@@ -287,7 +306,7 @@ func (s *Screen) SetCursorScrollUp() {
 			// is the same so there is no accounting to do for styles or any of
 			// that.
 			utils.Assert(oldPin.Node == s.Cursor.PagePin.Node)
-			s.Cursor.PagePin = s.Cursor.PagePin.Down(1)
+			*s.Cursor.PagePin = *s.Cursor.PagePin.Down(1)
 
 			pin := s.Cursor.PagePin
 			page := s.Cursor.PagePin.Node.Data
@@ -559,8 +578,20 @@ func (s *Screen) ClearCells(
 			row.Styled = false
 		}
 	}
+	if row.Hyperlink {
+		for i := fromX; i < toX; i++ {
+			cell := row.Cells[i]
+			if cell.HyperlinkID == 0 {
+				continue
+			}
+			page.Hyperlinks.Release(set.ID(cell.HyperlinkID))
+		}
+		if fromX == 0 && toX == s.Pages.Cols {
+			row.Hyperlink = false
+		}
+	}
 	for i := fromX; i < toX; i++ {
-		row.Cells[i] = s.blankCell()
+		*row.Cells[i] = *s.blankCell()
 	}
 }
 
@@ -571,7 +602,11 @@ func (s *Screen) blankCell() *pagepkg.Cell {
 		// If we have no style, then we can just return a blank cell
 		return &pagepkg.Cell{}
 	}
-	return s.Cursor.Style.BGCell()
+	// Styles without a background color also get a blank cell
+	if cell := s.Cursor.Style.BGCell(); cell != nil {
+		return cell
+	}
+	return &pagepkg.Cell{}
 }
 
 // Reset the screen according to the logic of DEC RIS sequence.
@@ -592,7 +627,12 @@ func (s *Screen) Reset() {
 		PageCell: cursorRAC.Cell,
 		PageRow:  cursorRAC.Row,
 		PagePin:  cursorPin,
+		Blinking: true,
+		Visible:  true,
 	}
+	s.SavedCursor = nil
+	s.Charset = charsets.NewState()
+	s.KittyKeyboard = kitty.KeyFlagStack{}
 }
 
 // Dump the screen to a string. The writer given should be buffered;
@@ -630,6 +670,64 @@ func (s *Screen) DumpString(
 	)
 }
 
+// DumpStringBetween dumps the text between the top-left and bottom-right
+// pins (both inclusive) to the writer. Unlike DumpString, the X coordinates
+// of the pins are honoured so this can be used to read a part of a row.
+// Trailing blanks of each row are trimmed and soft-wrapped rows are joined.
+//
+// Precondition: tl is before or equal to br.
+func (s *Screen) DumpStringBetween(w io.Writer, tl, br pagelist.Pin) error {
+	pin := tl
+	for {
+		last := pin.Node == br.Node && pin.Y == br.Y
+		pg := pin.Node.Data
+		row := pg.GetRow(pin.Y)
+		cells := pg.GetCells(row)
+
+		startX, endX := size.CellCountInt(0), size.CellCountInt(len(cells))
+		if pin.Node == tl.Node && pin.Y == tl.Y {
+			startX = tl.X
+		}
+		if last {
+			endX = min(endX, br.X+1)
+		}
+
+		blanks := 0
+		for x := startX; x < endX; x++ {
+			cell := cells[x]
+			switch cell.Wide {
+			case pagepkg.WideSpacerHead, pagepkg.WideSpacerTail:
+				continue
+			}
+			if !cell.HasText() {
+				blanks++
+				continue
+			}
+			if _, err := io.WriteString(w, strings.Repeat(" ", blanks)); err != nil {
+				return err
+			}
+			blanks = 0
+			if _, err := fmt.Fprintf(w, "%c", cell.ContentCP); err != nil {
+				return err
+			}
+		}
+
+		if last {
+			return nil
+		}
+		if !row.Wrap {
+			if _, err := w.Write([]byte{'\n'}); err != nil {
+				return err
+			}
+		}
+		next := pin.Down(1)
+		if next == nil {
+			return nil
+		}
+		pin = *next
+	}
+}
+
 // DumpStringWithFormatting dumps the screen content with ANSI formatting codes preserved
 func (s *Screen) DumpStringWithFormatting(w io.Writer, tl point.Tag) error {
 	tlPin := s.Pages.GetTopLeft(tl)
@@ -749,6 +847,24 @@ func (s *Screen) SetGraphicsRendition(attr *sgr.Attribute) {
 			Type: style.ColorTypeNone,
 		}
 
+	case sgr.AttributeTypePaletteFg:
+		s.Cursor.Style.ForegroundColor = style.Color{
+			Type:    style.ColorTypePalette,
+			Palette: attr.PaletteFg,
+		}
+
+	case sgr.AttributeTypePaletteBg:
+		s.Cursor.Style.BackgroundColor = style.Color{
+			Type:    style.ColorTypePalette,
+			Palette: attr.PaletteBg,
+		}
+
+	case sgr.AttributeTypePaletteUnderlineColor:
+		s.Cursor.Style.UnderlineColor = style.Color{
+			Type:    style.ColorTypePalette,
+			Palette: attr.PaletteUnderlineColor,
+		}
+
 	// We don't handle unknown attributes in the screen, so we just ignore
 	// them
 	case sgr.AttributeTypeUnknown:
@@ -857,6 +973,24 @@ func (s *Screen) SetAttribute(attr sgr.Attribute) {
 			Type: style.ColorTypeNone,
 		}
 
+	case sgr.AttributeTypePaletteFg:
+		s.Cursor.Style.ForegroundColor = style.Color{
+			Type:    style.ColorTypePalette,
+			Palette: attr.PaletteFg,
+		}
+
+	case sgr.AttributeTypePaletteBg:
+		s.Cursor.Style.BackgroundColor = style.Color{
+			Type:    style.ColorTypePalette,
+			Palette: attr.PaletteBg,
+		}
+
+	case sgr.AttributeTypePaletteUnderlineColor:
+		s.Cursor.Style.UnderlineColor = style.Color{
+			Type:    style.ColorTypePalette,
+			Palette: attr.PaletteUnderlineColor,
+		}
+
 	case sgr.AttributeTypeUnknown:
 		return
 	}
@@ -943,6 +1077,19 @@ func (s *Screen) ResizeWithoutReflow(cols, rows size.CellCountInt) {
 // the current style background color. This will clear all cells in the rows.
 // bl is optional and if not provided, the region will be cleared to the end.
 func (s *Screen) ClearRows(tl point.Point, bl *point.Point) {
+	for chunk := range s.Pages.PageIterator(tl, bl).Next() {
+		page := chunk.Node.Data
+		for y := chunk.StartY; y < chunk.EndY; y++ {
+			row := page.GetRow(y)
+			s.ClearCells(page, row, 0, page.Size.Cols)
+
+			// The row is blank, none of its metadata applies anymore.
+			row.Wrap = false
+			row.WrapContinuation = false
+			row.SemanticPrompt = pagepkg.SemanticPromptTypeUnknow
+			page.DirtyBitSet().Set(int(y))
+		}
+	}
 }
 
 // This is basically a really jank version of Terminal.printString. We
@@ -981,6 +1128,7 @@ func (s *Screen) testWriteString(text []uint8) error {
 			s.Cursor.PageCell.ContentTag = pagepkg.ContentTagCP
 			s.Cursor.PageCell.ContentCP = uint32(c)
 			s.Cursor.PageCell.StyleID = s.Cursor.StyleID
+			s.CursorSetHyperlink()
 			// s.Cursor.PageCell.Protected = s.Cursor.Protected
 
 			// if we have a ref-counted style, increase.
@@ -1007,12 +1155,14 @@ func (s *Screen) testWriteString(text []uint8) error {
 			s.Cursor.PageCell.ContentCP = uint32(c)
 			s.Cursor.PageCell.StyleID = s.Cursor.StyleID
 			s.Cursor.PageCell.Wide = pagepkg.WideWide
+			s.CursorSetHyperlink()
 
 			// Write our tail
 			s.SetCursorRight(1)
 			s.Cursor.PageCell.ContentTag = pagepkg.ContentTagCP
 			s.Cursor.PageCell.ContentCP = 0 // wide spacer tail
 			s.Cursor.PageCell.Wide = pagepkg.WideSpacerTail
+			s.CursorSetHyperlink()
 
 			// If we have a ref-counted style, increase twice.
 			if s.Cursor.StyleID != styleid.DefaultID {
@@ -1057,15 +1207,19 @@ func (s *Screen) CursorChangePin(newPin *pagelist.Pin) {
 		newPin.MarkDirty()
 	}
 
+	// The cursor pin is tracked by the page list so it is kept up to date
+	// when rows move, we update it in place rather than replacing it.
+	//
 	// If our pin is on the same page, then we can just update the pin.
 	// We don't need to migrate any state.
 	if s.Cursor.PagePin.Node == newPin.Node {
-		s.Cursor.PagePin = newPin
+		*s.Cursor.PagePin = *newPin
 		return
 	}
 	var oldStyle *style.Style = nil
 	if s.Cursor.StyleID != styleid.DefaultID {
-		oldStyle = &s.Cursor.Style
+		old := s.Cursor.Style
+		oldStyle = &old
 	}
 
 	if oldStyle != nil {
@@ -1074,12 +1228,75 @@ func (s *Screen) CursorChangePin(newPin *pagelist.Pin) {
 		s.ManualStyleUpdate()
 	}
 
-	s.Cursor.PagePin = newPin
+	// The hyperlink is page specific too, release it from the old page.
+	if s.Cursor.HyperlinkID != 0 {
+		page := s.Cursor.PagePin.Node.Data
+		page.Hyperlinks.Release(set.ID(s.Cursor.HyperlinkID))
+		s.Cursor.HyperlinkID = 0
+	}
+
+	*s.Cursor.PagePin = *newPin
 
 	if oldStyle != nil {
 		s.Cursor.Style = *oldStyle
 		s.ManualStyleUpdate()
 	}
+	s.cursorHyperlinkUpdate()
+}
+
+// Start a hyperlink (OSC 8). Cells printed until EndHyperlink are part of
+// the link. Links without an explicit id get a unique implicit one so that
+// separate links to the same URI aren't merged.
+func (s *Screen) StartHyperlink(uri, id string) {
+	link := pagepkg.Hyperlink{ExplicitID: id, URI: uri}
+	if id == "" {
+		s.hyperlinkImplicitID++
+		link.ImplicitID = s.hyperlinkImplicitID
+	}
+
+	s.EndHyperlink()
+	s.Cursor.Hyperlink = &link
+	s.cursorHyperlinkUpdate()
+}
+
+// End the current hyperlink, if any.
+func (s *Screen) EndHyperlink() {
+	if s.Cursor.HyperlinkID != 0 {
+		page := s.Cursor.PagePin.Node.Data
+		page.Hyperlinks.Release(set.ID(s.Cursor.HyperlinkID))
+	}
+	s.Cursor.Hyperlink = nil
+	s.Cursor.HyperlinkID = 0
+}
+
+// Add the cursor hyperlink to the page the cursor is on.
+func (s *Screen) cursorHyperlinkUpdate() {
+	if s.Cursor.Hyperlink == nil {
+		return
+	}
+	page := s.Cursor.PagePin.Node.Data
+	id := page.Hyperlinks.Add(*s.Cursor.Hyperlink)
+	s.Cursor.HyperlinkID = pagepkg.HyperlinkID(id)
+}
+
+// Set the hyperlink of the cell under the cursor to the cursor hyperlink.
+// This takes care of the ref counts so it must be used whenever a cell is
+// written.
+func (s *Screen) CursorSetHyperlink() {
+	cell := s.Cursor.PageCell
+	if cell.HyperlinkID == s.Cursor.HyperlinkID {
+		return
+	}
+
+	page := s.Cursor.PagePin.Node.Data
+	if cell.HyperlinkID != 0 {
+		page.Hyperlinks.Release(set.ID(cell.HyperlinkID))
+	}
+	cell.HyperlinkID = s.Cursor.HyperlinkID
+	if cell.HyperlinkID != 0 {
+		page.Hyperlinks.Use(set.ID(cell.HyperlinkID))
+		s.Cursor.PageRow.Hyperlink = true
+	}
 }
 
 // CursorCellRight implements Screen.
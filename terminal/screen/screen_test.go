@@ -4,8 +4,10 @@ import (
 	"bytes"
 	"testing"
 
+	"github.com/hnimtadd/termio/terminal/coordinate"
 	"github.com/hnimtadd/termio/terminal/point"
 	"github.com/hnimtadd/termio/terminal/sgr"
+	"github.com/hnimtadd/termio/terminal/size"
 	styleid "github.com/hnimtadd/termio/terminal/style/id"
 	"github.com/stretchr/testify/assert"
 )
@@ -110,3 +112,112 @@ func TestScreen_ResetWithUnset(t *testing.T) {
 	assert.Equal(t, styleid.ID(0), s.Cursor.StyleID)
 	assert.Equal(t, 0, page.Styles.Count())
 }
+
+func TestScreen_Hyperlink(t *testing.T) {
+	s := NewScreen(10, 5)
+	page := s.Cursor.PagePin.Node.Data
+
+	assert.NoError(t, s.testWriteString([]byte("a ")))
+	s.StartHyperlink("https://example.com", "")
+	assert.NoError(t, s.testWriteString([]byte("link text!")))
+	s.EndHyperlink()
+	assert.NoError(t, s.testWriteString([]byte(" b")))
+
+	// Every cell of the link holds a reference, the cursor released its own.
+	assert.Equal(t, 1, page.Hyperlinks.Count())
+
+	at := func(x, y size.CellCountInt) point.Point {
+		return point.Point{
+			Tag:        point.TagActive,
+			Coordinate: coordinate.Point[size.CellCountInt]{X: x, Y: y},
+		}
+	}
+	assert.Nil(t, s.HyperlinkAt(at(0, 0)))
+	assert.Nil(t, s.HyperlinkAt(at(3, 1)))
+
+	// The link wrapped onto the second row.
+	link := s.HyperlinkAt(at(1, 1))
+	if assert.NotNil(t, link) {
+		assert.Equal(t, "https://example.com", link.URI)
+		assert.Empty(t, link.ID)
+		assert.Equal(t, at(2, 0), link.Start)
+		assert.Equal(t, at(1, 1), link.End)
+	}
+
+	// Clearing the cells releases the link.
+	s.ClearCells(page, page.GetRow(0), 0, 10)
+	s.ClearCells(page, page.GetRow(1), 0, 10)
+	assert.Equal(t, 0, page.Hyperlinks.Count())
+	assert.Nil(t, s.HyperlinkAt(at(3, 0)))
+}
+
+func TestScreen_HyperlinkExplicitID(t *testing.T) {
+	s := NewScreen(10, 5)
+
+	// Two links to the same URI without an id are distinct links.
+	s.StartHyperlink("https://example.com", "")
+	assert.NoError(t, s.testWriteString([]byte("one")))
+	s.StartHyperlink("https://example.com", "")
+	assert.NoError(t, s.testWriteString([]byte("two")))
+	s.EndHyperlink()
+
+	at := func(x, y size.CellCountInt) point.Point {
+		return point.Point{
+			Tag:        point.TagActive,
+			Coordinate: coordinate.Point[size.CellCountInt]{X: x, Y: y},
+		}
+	}
+	link := s.HyperlinkAt(at(0, 0))
+	if assert.NotNil(t, link) {
+		assert.Equal(t, at(0, 0), link.Start)
+		assert.Equal(t, at(2, 0), link.End)
+	}
+
+	// A link printed on several rows with the same id, e.g. by a TUI with
+	// a border, is a single link.
+	for y := size.CellCountInt(1); y < 4; y++ {
+		s.SetCursorAbs(0, y)
+		assert.NoError(t, s.testWriteString([]byte("|")))
+		s.StartHyperlink("https://example.com/doc", "doc")
+		assert.NoError(t, s.testWriteString([]byte("docs")))
+		s.EndHyperlink()
+		assert.NoError(t, s.testWriteString([]byte("|")))
+	}
+	link = s.HyperlinkAt(at(2, 2))
+	if assert.NotNil(t, link) {
+		assert.Equal(t, "https://example.com/doc", link.URI)
+		assert.Equal(t, "doc", link.ID)
+		assert.Equal(t, at(1, 1), link.Start)
+		assert.Equal(t, at(4, 3), link.End)
+	}
+	assert.Nil(t, s.HyperlinkAt(at(0, 2)))
+}
+
+func TestScreen_HyperlinkScrolledOff(t *testing.T) {
+	s := NewScreen(10, 3)
+	s.NoScrollback = true
+	page := s.Cursor.PagePin.Node.Data
+
+	s.StartHyperlink("https://example.com/a", "")
+	assert.NoError(t, s.testWriteString([]byte("a\n")))
+	s.StartHyperlink("https://example.com/b", "")
+	assert.NoError(t, s.testWriteString([]byte("b\n")))
+	s.EndHyperlink()
+	assert.Equal(t, 2, page.Hyperlinks.Count())
+
+	// Rows scrolled off the screen release their links.
+	assert.NoError(t, s.testWriteString([]byte("\n")))
+	assert.Equal(t, 1, page.Hyperlinks.Count())
+	assert.NoError(t, s.testWriteString([]byte("\n")))
+	assert.Equal(t, 0, page.Hyperlinks.Count())
+	assert.False(t, page.GetRow(0).Hyperlink)
+
+	// Same for the rows erased when scrolling a region.
+	s.SetCursorAbs(0, 0)
+	s.StartHyperlink("https://example.com/c", "")
+	assert.NoError(t, s.testWriteString([]byte("c")))
+	s.EndHyperlink()
+	assert.Equal(t, 1, page.Hyperlinks.Count())
+	s.Pages.EraseRowsBounded(point.Point{Tag: point.TagActive}, 1)
+	assert.Equal(t, 0, page.Hyperlinks.Count())
+}
@@ -36,4 +36,43 @@ const (
 	ELModeAll   ELMode = 2
 )
 
+// Tabulation Clear mode
+type TBCMode uint8
+
+const (
+	TBCModeCurrent TBCMode = 0 // Clear the tab stop at the cursor
+	TBCModeAll     TBCMode = 3 // Clear all tab stops
+)
+
+// Cursor style set with DECSCUSR
+type CursorStyle uint8
+
+const (
+	CursorStyleDefault           CursorStyle = 0 // Same as a blinking block
+	CursorStyleBlinkingBlock     CursorStyle = 1
+	CursorStyleSteadyBlock       CursorStyle = 2
+	CursorStyleBlinkingUnderline CursorStyle = 3
+	CursorStyleSteadyUnderline   CursorStyle = 4
+	CursorStyleBlinkingBar       CursorStyle = 5
+	CursorStyleSteadyBar         CursorStyle = 6
+)
+
+// Device Attributes request
+type DARequest uint8
+
+const (
+	DARequestPrimary   DARequest = 0 // CSI c
+	DARequestSecondary DARequest = 1 // CSI > c
+	DARequestTertiary  DARequest = 2 // CSI = c
+)
+
+// Device Status Report request
+type DSRRequest uint8
+
+const (
+	DSRRequestOperatingStatus        DSRRequest = 0 // CSI 5 n
+	DSRRequestCursorPosition         DSRRequest = 1 // CSI 6 n
+	DSRRequestExtendedCursorPosition DSRRequest = 2 // CSI ? 6 n (DECXCPR)
+)
+
 type SGR uint8
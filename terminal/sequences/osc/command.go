@@ -1,3 +1,257 @@
 package osc
 
-type Command struct{}
+import (
+	"fmt"
+
+	"github.com/hnimtadd/termio/terminal/color"
+)
+
+// CommandType is the kind of the OSC command parsed by the Parser.
+type CommandType int
+
+const (
+	// CommandTypeChangeWindowTitle changes the window title and/or the icon
+	// name (OSC 0, OSC 1, OSC 2).
+	CommandTypeChangeWindowTitle CommandType = iota
+	// CommandTypeReportPwd reports the current working directory of the
+	// shell (OSC 7).
+	CommandTypeReportPwd
+	// CommandTypeHyperlinkStart starts a hyperlink (OSC 8 with an URI).
+	CommandTypeHyperlinkStart
+	// CommandTypeHyperlinkEnd ends the current hyperlink (OSC 8 without an
+	// URI).
+	CommandTypeHyperlinkEnd
+	// CommandTypeColorOperation sets, queries or resets dynamic colors
+	// (OSC 4, OSC 10-12, OSC 104, OSC 110-112).
+	CommandTypeColorOperation
+	// CommandTypeClipboardContents sets or queries the clipboard (OSC 52).
+	CommandTypeClipboardContents
+	// CommandTypePromptStart marks the start of a shell prompt (OSC 133;A).
+	CommandTypePromptStart
+	// CommandTypePromptEnd marks the end of a shell prompt and the start of
+	// the user input (OSC 133;B).
+	CommandTypePromptEnd
+	// CommandTypeEndOfInput marks the end of the user input and the start
+	// of the command output (OSC 133;C).
+	CommandTypeEndOfInput
+	// CommandTypeEndOfCommand marks the end of the command output
+	// (OSC 133;D).
+	CommandTypeEndOfCommand
+	// CommandTypeShowDesktopNotification shows a desktop notification
+	// (OSC 9, OSC 777;notify).
+	CommandTypeShowDesktopNotification
+)
+
+func (t CommandType) String() string {
+	switch t {
+	case CommandTypeChangeWindowTitle:
+		return "ChangeWindowTitle"
+	case CommandTypeReportPwd:
+		return "ReportPwd"
+	case CommandTypeHyperlinkStart:
+		return "HyperlinkStart"
+	case CommandTypeHyperlinkEnd:
+		return "HyperlinkEnd"
+	case CommandTypeColorOperation:
+		return "ColorOperation"
+	case CommandTypeClipboardContents:
+		return "ClipboardContents"
+	case CommandTypePromptStart:
+		return "PromptStart"
+	case CommandTypePromptEnd:
+		return "PromptEnd"
+	case CommandTypeEndOfInput:
+		return "EndOfInput"
+	case CommandTypeEndOfCommand:
+		return "EndOfCommand"
+	case CommandTypeShowDesktopNotification:
+		return "ShowDesktopNotification"
+	default:
+		return "Unknown"
+	}
+}
+
+// Terminator is the byte sequence that terminated the OSC command. Replies
+// to a query should use the same terminator as the query.
+type Terminator uint8
+
+const (
+	// TerminatorST is ESC \. The 8-bit ST (0x9C) doesn't end an OSC, it is
+	// part of the UTF-8 payload.
+	TerminatorST Terminator = iota
+	// TerminatorBEL is the xterm-specific BEL (0x07).
+	TerminatorBEL
+)
+
+// String returns the byte representation of the terminator.
+func (t Terminator) String() string {
+	switch t {
+	case TerminatorBEL:
+		return "\x07"
+	default:
+		return "\x1b\\"
+	}
+}
+
+// Command is an OSC command parsed by the Parser. Only the data field
+// matching the Type is set.
+type Command struct {
+	Type       CommandType
+	Terminator Terminator
+
+	// WindowTitleData is set for CommandTypeChangeWindowTitle.
+	WindowTitleData *WindowTitle
+
+	// ReportPwdData is the raw URI reported by the shell, set for
+	// CommandTypeReportPwd.
+	ReportPwdData string
+
+	// HyperlinkStartData is set for CommandTypeHyperlinkStart.
+	HyperlinkStartData *Hyperlink
+
+	// ColorOperationData is set for CommandTypeColorOperation.
+	ColorOperationData []ColorOperation
+
+	// ClipboardData is set for CommandTypeClipboardContents.
+	ClipboardData *Clipboard
+
+	// PromptStartData is set for CommandTypePromptStart.
+	PromptStartData *PromptStart
+
+	// EndOfCommandData is set for CommandTypeEndOfCommand.
+	EndOfCommandData *EndOfCommand
+
+	// NotificationData is set for CommandTypeShowDesktopNotification.
+	NotificationData *Notification
+}
+
+func (c Command) String() string {
+	return fmt.Sprintf("OSC %v", c.Type)
+}
+
+// TitleTarget selects which of the window title and icon name is affected.
+// The values match the OSC numbers.
+type TitleTarget uint8
+
+const (
+	TitleTargetBoth   TitleTarget = 0
+	TitleTargetIcon   TitleTarget = 1
+	TitleTargetWindow TitleTarget = 2
+)
+
+// WindowTitle is the data of OSC 0, OSC 1 and OSC 2.
+type WindowTitle struct {
+	Target TitleTarget
+	Title  string
+}
+
+// Hyperlink is the data of OSC 8. ID is the optional id= parameter which
+// allows cells that are not adjacent to belong to the same link.
+type Hyperlink struct {
+	ID  string
+	URI string
+}
+
+// ColorOperationType is the kind of a ColorOperation.
+type ColorOperationType uint8
+
+const (
+	// ColorOperationSet sets the target to RGB.
+	ColorOperationSet ColorOperationType = iota
+	// ColorOperationQuery reports the current value of the target.
+	ColorOperationQuery
+	// ColorOperationReset resets the target to its default value.
+	ColorOperationReset
+	// ColorOperationResetPalette resets every palette entry to its default
+	// value. The target is ignored.
+	ColorOperationResetPalette
+)
+
+// ColorTargetType is the kind of dynamic color a ColorOperation applies to.
+type ColorTargetType uint8
+
+const (
+	ColorTargetPalette ColorTargetType = iota
+	ColorTargetForeground
+	ColorTargetBackground
+	ColorTargetCursor
+)
+
+// ColorTarget is the dynamic color a ColorOperation applies to. Index is
+// only meaningful for ColorTargetPalette.
+type ColorTarget struct {
+	Type  ColorTargetType
+	Index uint8
+}
+
+// ColorOperation is a single operation of OSC 4, OSC 10-12, OSC 104 or
+// OSC 110-112. A single OSC may contain multiple operations.
+type ColorOperation struct {
+	Type   ColorOperationType
+	Target ColorTarget
+	RGB    color.RGB
+}
+
+// Clipboard is the data of OSC 52. Kind is the selection target
+// ('c' clipboard, 'p' primary, 's' selection, ...) and Data the base64
+// encoded contents, or "?" for a read request.
+type Clipboard struct {
+	Kind uint8
+	Data string
+}
+
+// IsQuery reports whether the clipboard command is a read request.
+func (c Clipboard) IsQuery() bool {
+	return c.Data == "?"
+}
+
+// PromptKind is the kind of prompt for OSC 133;A (k= option).
+type PromptKind uint8
+
+const (
+	PromptKindPrimary PromptKind = iota
+	PromptKindRight
+	PromptKindContinuation
+	PromptKindSecondary
+)
+
+func (k PromptKind) String() string {
+	switch k {
+	case PromptKindRight:
+		return "right"
+	case PromptKindContinuation:
+		return "continuation"
+	case PromptKindSecondary:
+		return "secondary"
+	default:
+		return "primary"
+	}
+}
+
+// PromptStart is the data of OSC 133;A.
+type PromptStart struct {
+	// Aid is the application identifier (aid= option)
+	Aid string
+	// Kind is the prompt kind (k= option)
+	Kind PromptKind
+	// Redraw reports whether the shell redraws the prompt on resize
+	// (redraw= option, defaults to true)
+	Redraw bool
+	// Click is the click-to-move-cursor mode requested by the shell
+	// (cl= option), one of "line", "m", "v" or "w", empty when unset.
+	Click string
+}
+
+// EndOfCommand is the data of OSC 133;D.
+type EndOfCommand struct {
+	// ExitCode is nil when the shell did not report it.
+	ExitCode *int
+	// Aid is the application identifier (aid= option)
+	Aid string
+}
+
+// Notification is the data of OSC 9 and OSC 777;notify.
+type Notification struct {
+	Title string
+	Body  string
+}
@@ -1,12 +1,359 @@
 package osc
 
-type Parser struct{}
+import (
+	"strconv"
+	"strings"
 
-func (p *Parser) End() *Command {
-	return &Command{}
+	"github.com/hnimtadd/termio/terminal/color"
+)
+
+// MaxBufferSize is the maximum size of an OSC payload. Clipboard contents
+// (OSC 52) are the largest payload we expect, anything larger than this is
+// dropped.
+const MaxBufferSize = 1 << 20
+
+// Parser for OSC sequences.
+//
+// The parser buffers the payload of the OSC (everything between the OSC
+// introducer and the terminator) and parses it into a typed Command when
+// the sequence is terminated.
+//
+// Supported OSC commands:
+//
+//   - OSC 0, 1, 2: change window title and/or icon name
+//   - OSC 4, 10, 11, 12, 104, 110, 111, 112: dynamic colors
+//   - OSC 7: report pwd
+//   - OSC 8: hyperlinks
+//   - OSC 9, 777: desktop notifications
+//   - OSC 52: clipboard
+//   - OSC 133: semantic prompts
+type Parser struct {
+	buf      []uint8
+	overflow bool
 }
 
+func NewParser() *Parser {
+	return &Parser{}
+}
+
+// Reset resets the parser state, this is called when the OSC introducer is
+// seen.
+func (p *Parser) Reset() {
+	p.buf = p.buf[:0]
+	p.overflow = false
+}
+
+// Next consumes the next byte of the OSC payload.
 func (p *Parser) Next(c uint8) {
+	if p.overflow {
+		return
+	}
+	if len(p.buf) >= MaxBufferSize {
+		p.overflow = true
+		return
+	}
+	p.buf = append(p.buf, c)
 }
 
-func (p *Parser) Reset() {}
+// End is called when the OSC is terminated, terminator is the byte that
+// ended the sequence: BEL or ESC (the beginning of ST).
+//
+// It returns nil if the sequence was cancelled, the payload was too large,
+// or the command is invalid or not supported.
+func (p *Parser) End(terminator uint8) *Command {
+	defer p.Reset()
+
+	var term Terminator
+	switch terminator {
+	case 0x07:
+		term = TerminatorBEL
+	case 0x1B:
+		term = TerminatorST
+	default:
+		// CAN, SUB and other bytes cancel the sequence.
+		return nil
+	}
+	if p.overflow {
+		return nil
+	}
+
+	cmd := parseCommand(string(p.buf))
+	if cmd != nil {
+		cmd.Terminator = term
+	}
+	return cmd
+}
+
+// parseCommand parses the OSC payload in form of Ps ; Pt
+func parseCommand(data string) *Command {
+	ps, pt, _ := strings.Cut(data, ";")
+	num, err := strconv.ParseUint(ps, 10, 16)
+	if err != nil {
+		return nil
+	}
+
+	switch num {
+	case 0, 1, 2:
+		return &Command{
+			Type: CommandTypeChangeWindowTitle,
+			WindowTitleData: &WindowTitle{
+				Target: TitleTarget(num),
+				Title:  pt,
+			},
+		}
+	case 4:
+		return parsePaletteColors(pt)
+	case 7:
+		return &Command{
+			Type:          CommandTypeReportPwd,
+			ReportPwdData: pt,
+		}
+	case 8:
+		return parseHyperlink(pt)
+	case 9:
+		return &Command{
+			Type:             CommandTypeShowDesktopNotification,
+			NotificationData: &Notification{Body: pt},
+		}
+	case 10, 11, 12:
+		return parseDynamicColors(int(num), pt)
+	case 52:
+		return parseClipboard(pt)
+	case 104:
+		return parseResetPaletteColors(pt)
+	case 110, 111, 112:
+		return &Command{
+			Type: CommandTypeColorOperation,
+			ColorOperationData: []ColorOperation{{
+				Type:   ColorOperationReset,
+				Target: dynamicColorTarget(int(num) - 100),
+			}},
+		}
+	case 133:
+		return parseSemanticPrompt(pt)
+	case 777:
+		return parseRxvtExtension(pt)
+	default:
+		return nil
+	}
+}
+
+// parseHyperlink parses OSC 8 ; params ; URI where params is a list of
+// key=value separated by ':'.
+func parseHyperlink(data string) *Command {
+	params, uri, found := strings.Cut(data, ";")
+	if !found {
+		return nil
+	}
+	if uri == "" {
+		return &Command{Type: CommandTypeHyperlinkEnd}
+	}
+
+	link := &Hyperlink{URI: uri}
+	for param := range strings.SplitSeq(params, ":") {
+		key, value, _ := strings.Cut(param, "=")
+		if key == "id" {
+			link.ID = value
+		}
+	}
+	return &Command{
+		Type:               CommandTypeHyperlinkStart,
+		HyperlinkStartData: link,
+	}
+}
+
+// parsePaletteColors parses OSC 4 ; c ; spec [; c ; spec ...]
+func parsePaletteColors(data string) *Command {
+	parts := strings.Split(data, ";")
+	if len(parts) < 2 || len(parts)%2 != 0 {
+		return nil
+	}
+
+	ops := make([]ColorOperation, 0, len(parts)/2)
+	for i := 0; i < len(parts); i += 2 {
+		index, err := strconv.ParseUint(parts[i], 10, 8)
+		if err != nil {
+			return nil
+		}
+		op, ok := parseColorOperation(
+			ColorTarget{Type: ColorTargetPalette, Index: uint8(index)},
+			parts[i+1],
+		)
+		if !ok {
+			return nil
+		}
+		ops = append(ops, op)
+	}
+	return &Command{
+		Type:               CommandTypeColorOperation,
+		ColorOperationData: ops,
+	}
+}
+
+// parseResetPaletteColors parses OSC 104 [; c ...], without any index the
+// whole palette is reset.
+func parseResetPaletteColors(data string) *Command {
+	if data == "" {
+		return &Command{
+			Type: CommandTypeColorOperation,
+			ColorOperationData: []ColorOperation{
+				{Type: ColorOperationResetPalette},
+			},
+		}
+	}
+
+	var ops []ColorOperation
+	for part := range strings.SplitSeq(data, ";") {
+		index, err := strconv.ParseUint(part, 10, 8)
+		if err != nil {
+			return nil
+		}
+		ops = append(ops, ColorOperation{
+			Type:   ColorOperationReset,
+			Target: ColorTarget{Type: ColorTargetPalette, Index: uint8(index)},
+		})
+	}
+	return &Command{
+		Type:               CommandTypeColorOperation,
+		ColorOperationData: ops,
+	}
+}
+
+// parseDynamicColors parses OSC 10, 11 and 12. As in xterm, each extra
+// spec applies to the next dynamic color, e.g. OSC 10 ; fg ; bg sets both
+// the foreground and the background.
+func parseDynamicColors(num int, data string) *Command {
+	var ops []ColorOperation
+	for spec := range strings.SplitSeq(data, ";") {
+		if num > 12 {
+			break
+		}
+		op, ok := parseColorOperation(dynamicColorTarget(num), spec)
+		if !ok {
+			return nil
+		}
+		ops = append(ops, op)
+		num++
+	}
+	return &Command{
+		Type:               CommandTypeColorOperation,
+		ColorOperationData: ops,
+	}
+}
+
+func dynamicColorTarget(num int) ColorTarget {
+	switch num {
+	case 10:
+		return ColorTarget{Type: ColorTargetForeground}
+	case 11:
+		return ColorTarget{Type: ColorTargetBackground}
+	default:
+		return ColorTarget{Type: ColorTargetCursor}
+	}
+}
+
+func parseColorOperation(target ColorTarget, spec string) (ColorOperation, bool) {
+	if spec == "?" {
+		return ColorOperation{Type: ColorOperationQuery, Target: target}, true
+	}
+	rgb, err := color.ParseSpec(spec)
+	if err != nil {
+		return ColorOperation{}, false
+	}
+	return ColorOperation{Type: ColorOperationSet, Target: target, RGB: rgb}, true
+}
+
+// parseClipboard parses OSC 52 ; Pc ; Pd
+func parseClipboard(data string) *Command {
+	kinds, contents, found := strings.Cut(data, ";")
+	if !found {
+		return nil
+	}
+	// Only the first selection target is used, default to the clipboard.
+	var kind uint8 = 'c'
+	if kinds != "" {
+		kind = kinds[0]
+	}
+	return &Command{
+		Type: CommandTypeClipboardContents,
+		ClipboardData: &Clipboard{
+			Kind: kind,
+			Data: contents,
+		},
+	}
+}
+
+// parseSemanticPrompt parses OSC 133 ; <A|B|C|D> [; options]
+func parseSemanticPrompt(data string) *Command {
+	parts := strings.Split(data, ";")
+	switch parts[0] {
+	case "A":
+		prompt := &PromptStart{Redraw: true}
+		for _, option := range parts[1:] {
+			key, value, _ := strings.Cut(option, "=")
+			switch key {
+			case "aid":
+				prompt.Aid = value
+			case "cl":
+				prompt.Click = value
+			case "redraw":
+				prompt.Redraw = value != "0"
+			case "k":
+				switch value {
+				case "i":
+					prompt.Kind = PromptKindPrimary
+				case "r":
+					prompt.Kind = PromptKindRight
+				case "c":
+					prompt.Kind = PromptKindContinuation
+				case "s":
+					prompt.Kind = PromptKindSecondary
+				}
+			}
+		}
+		return &Command{
+			Type:            CommandTypePromptStart,
+			PromptStartData: prompt,
+		}
+	case "B":
+		return &Command{Type: CommandTypePromptEnd}
+	case "C":
+		return &Command{Type: CommandTypeEndOfInput}
+	case "D":
+		end := &EndOfCommand{}
+		for _, option := range parts[1:] {
+			key, value, found := strings.Cut(option, "=")
+			if !found {
+				if code, err := strconv.Atoi(option); err == nil {
+					end.ExitCode = &code
+				}
+				continue
+			}
+			if key == "aid" {
+				end.Aid = value
+			}
+		}
+		return &Command{
+			Type:             CommandTypeEndOfCommand,
+			EndOfCommandData: end,
+		}
+	default:
+		return nil
+	}
+}
+
+// parseRxvtExtension parses OSC 777 ; notify ; title ; body
+func parseRxvtExtension(data string) *Command {
+	ext, rest, _ := strings.Cut(data, ";")
+	if ext != "notify" {
+		return nil
+	}
+	title, body, _ := strings.Cut(rest, ";")
+	return &Command{
+		Type: CommandTypeShowDesktopNotification,
+		NotificationData: &Notification{
+			Title: title,
+			Body:  body,
+		},
+	}
+}
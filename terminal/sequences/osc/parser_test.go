@@ -0,0 +1,224 @@
+package osc
+
+import (
+	"testing"
+
+	"github.com/hnimtadd/termio/terminal/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parse(input string, terminator uint8) *Command {
+	p := NewParser()
+	for i := range len(input) {
+		p.Next(input[i])
+	}
+	return p.End(terminator)
+}
+
+func TestParser_WindowTitle(t *testing.T) {
+	tcs := []struct {
+		input  string
+		target TitleTarget
+		title  string
+	}{
+		{"0;hello world", TitleTargetBoth, "hello world"},
+		{"1;icon", TitleTargetIcon, "icon"},
+		{"2;a;b;c", TitleTargetWindow, "a;b;c"},
+		{"2;", TitleTargetWindow, ""},
+		{"2;héllo", TitleTargetWindow, "héllo"},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.input, func(t *testing.T) {
+			cmd := parse(tc.input, 0x07)
+			require.NotNil(t, cmd)
+			assert.Equal(t, CommandTypeChangeWindowTitle, cmd.Type)
+			assert.Equal(t, TerminatorBEL, cmd.Terminator)
+			assert.Equal(t, tc.target, cmd.WindowTitleData.Target)
+			assert.Equal(t, tc.title, cmd.WindowTitleData.Title)
+		})
+	}
+}
+
+func TestParser_Terminator(t *testing.T) {
+	cmd := parse("2;title", 0x1B)
+	require.NotNil(t, cmd)
+	assert.Equal(t, TerminatorST, cmd.Terminator)
+	assert.Equal(t, "\x1b\\", cmd.Terminator.String())
+
+	cmd = parse("2;title", 0x07)
+	require.NotNil(t, cmd)
+	assert.Equal(t, TerminatorBEL, cmd.Terminator)
+	assert.Equal(t, "\x07", cmd.Terminator.String())
+
+	// CAN cancels the sequence
+	assert.Nil(t, parse("2;title", 0x18))
+}
+
+func TestParser_Invalid(t *testing.T) {
+	for _, input := range []string{"", "abc;def", "999;x", "133;Z", "8;no-uri-separator", "4;1", "777;other;a;b"} {
+		t.Run(input, func(t *testing.T) {
+			assert.Nil(t, parse(input, 0x07))
+		})
+	}
+}
+
+func TestParser_ReportPwd(t *testing.T) {
+	cmd := parse("7;file://host/home/user", 0x07)
+	require.NotNil(t, cmd)
+	assert.Equal(t, CommandTypeReportPwd, cmd.Type)
+	assert.Equal(t, "file://host/home/user", cmd.ReportPwdData)
+}
+
+func TestParser_Hyperlink(t *testing.T) {
+	cmd := parse("8;id=foo:bar=baz;https://example.com/a;b", 0x07)
+	require.NotNil(t, cmd)
+	assert.Equal(t, CommandTypeHyperlinkStart, cmd.Type)
+	assert.Equal(t, "foo", cmd.HyperlinkStartData.ID)
+	assert.Equal(t, "https://example.com/a;b", cmd.HyperlinkStartData.URI)
+
+	cmd = parse("8;;https://example.com", 0x07)
+	require.NotNil(t, cmd)
+	assert.Equal(t, CommandTypeHyperlinkStart, cmd.Type)
+	assert.Empty(t, cmd.HyperlinkStartData.ID)
+
+	cmd = parse("8;;", 0x07)
+	require.NotNil(t, cmd)
+	assert.Equal(t, CommandTypeHyperlinkEnd, cmd.Type)
+}
+
+func TestParser_ColorOperation(t *testing.T) {
+	t.Run("palette set and query", func(t *testing.T) {
+		cmd := parse("4;1;rgb:ff/80/00;2;?", 0x07)
+		require.NotNil(t, cmd)
+		assert.Equal(t, CommandTypeColorOperation, cmd.Type)
+		assert.Equal(t, []ColorOperation{
+			{
+				Type:   ColorOperationSet,
+				Target: ColorTarget{Type: ColorTargetPalette, Index: 1},
+				RGB:    color.RGB{R: 0xFF, G: 0x80, B: 0x00},
+			},
+			{
+				Type:   ColorOperationQuery,
+				Target: ColorTarget{Type: ColorTargetPalette, Index: 2},
+			},
+		}, cmd.ColorOperationData)
+	})
+
+	t.Run("dynamic colors", func(t *testing.T) {
+		cmd := parse("10;#ffffff;?", 0x07)
+		require.NotNil(t, cmd)
+		assert.Equal(t, []ColorOperation{
+			{
+				Type:   ColorOperationSet,
+				Target: ColorTarget{Type: ColorTargetForeground},
+				RGB:    color.RGB{R: 0xFF, G: 0xFF, B: 0xFF},
+			},
+			{
+				Type:   ColorOperationQuery,
+				Target: ColorTarget{Type: ColorTargetBackground},
+			},
+		}, cmd.ColorOperationData)
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		cmd := parse("104", 0x07)
+		require.NotNil(t, cmd)
+		assert.Equal(t, []ColorOperation{{Type: ColorOperationResetPalette}},
+			cmd.ColorOperationData)
+
+		cmd = parse("104;3;4", 0x07)
+		require.NotNil(t, cmd)
+		assert.Len(t, cmd.ColorOperationData, 2)
+		assert.Equal(t, ColorOperationReset, cmd.ColorOperationData[1].Type)
+		assert.EqualValues(t, 4, cmd.ColorOperationData[1].Target.Index)
+
+		cmd = parse("112", 0x07)
+		require.NotNil(t, cmd)
+		assert.Equal(t, []ColorOperation{{
+			Type:   ColorOperationReset,
+			Target: ColorTarget{Type: ColorTargetCursor},
+		}}, cmd.ColorOperationData)
+	})
+}
+
+func TestParser_Clipboard(t *testing.T) {
+	cmd := parse("52;c;aGVsbG8=", 0x07)
+	require.NotNil(t, cmd)
+	assert.Equal(t, CommandTypeClipboardContents, cmd.Type)
+	assert.EqualValues(t, 'c', cmd.ClipboardData.Kind)
+	assert.Equal(t, "aGVsbG8=", cmd.ClipboardData.Data)
+	assert.False(t, cmd.ClipboardData.IsQuery())
+
+	cmd = parse("52;;?", 0x07)
+	require.NotNil(t, cmd)
+	assert.EqualValues(t, 'c', cmd.ClipboardData.Kind)
+	assert.True(t, cmd.ClipboardData.IsQuery())
+
+	cmd = parse("52;ps;?", 0x07)
+	require.NotNil(t, cmd)
+	assert.EqualValues(t, 'p', cmd.ClipboardData.Kind)
+}
+
+func TestParser_SemanticPrompt(t *testing.T) {
+	cmd := parse("133;A;aid=123;cl=m;k=c;redraw=0", 0x07)
+	require.NotNil(t, cmd)
+	assert.Equal(t, CommandTypePromptStart, cmd.Type)
+	assert.Equal(t, PromptStart{
+		Aid:    "123",
+		Kind:   PromptKindContinuation,
+		Redraw: false,
+		Click:  "m",
+	}, *cmd.PromptStartData)
+
+	cmd = parse("133;A", 0x07)
+	require.NotNil(t, cmd)
+	assert.Equal(t, PromptStart{Redraw: true}, *cmd.PromptStartData)
+
+	cmd = parse("133;B", 0x07)
+	require.NotNil(t, cmd)
+	assert.Equal(t, CommandTypePromptEnd, cmd.Type)
+
+	cmd = parse("133;C", 0x07)
+	require.NotNil(t, cmd)
+	assert.Equal(t, CommandTypeEndOfInput, cmd.Type)
+
+	cmd = parse("133;D;127;aid=123", 0x07)
+	require.NotNil(t, cmd)
+	assert.Equal(t, CommandTypeEndOfCommand, cmd.Type)
+	require.NotNil(t, cmd.EndOfCommandData.ExitCode)
+	assert.Equal(t, 127, *cmd.EndOfCommandData.ExitCode)
+	assert.Equal(t, "123", cmd.EndOfCommandData.Aid)
+
+	cmd = parse("133;D", 0x07)
+	require.NotNil(t, cmd)
+	assert.Nil(t, cmd.EndOfCommandData.ExitCode)
+}
+
+func TestParser_Notification(t *testing.T) {
+	cmd := parse("9;build finished", 0x07)
+	require.NotNil(t, cmd)
+	assert.Equal(t, CommandTypeShowDesktopNotification, cmd.Type)
+	assert.Equal(t, Notification{Body: "build finished"}, *cmd.NotificationData)
+
+	cmd = parse("777;notify;Title;Body;with;semicolons", 0x07)
+	require.NotNil(t, cmd)
+	assert.Equal(t, Notification{Title: "Title", Body: "Body;with;semicolons"},
+		*cmd.NotificationData)
+}
+
+func TestParser_Reset(t *testing.T) {
+	p := NewParser()
+	for _, c := range []byte("2;first") {
+		p.Next(c)
+	}
+	require.NotNil(t, p.End(0x07))
+
+	// End resets the buffer so the next command doesn't see stale data.
+	for _, c := range []byte("1;second") {
+		p.Next(c)
+	}
+	cmd := p.End(0x07)
+	require.NotNil(t, cmd)
+	assert.Equal(t, "second", cmd.WindowTitleData.Title)
+}
@@ -67,7 +67,7 @@ func NewRefCountedSet(opts Options) *RefCountedSet {
 	return &RefCountedSet{
 		items:    make([]*elem, cap),
 		table:    make(map[uint64]ID, cap),
-		pslStats: make([]int64, 32),
+		pslStats: make([]int64, cap+1), // A probe can't be longer than the table.
 		maxPSL:   0,
 		nextID:   1, // Start from 1, since 0 is reserved for unused items.
 	}
@@ -101,6 +101,12 @@ checkLoop:
 		return id
 	}
 
+	// Out of IDs, make room before inserting.
+	if int(s.nextID) >= len(items) {
+		s.grow()
+		items = s.items
+	}
+
 	id := s.Insert(uint64(s.nextID), value)
 	items[id].meta.ref += 1
 	if items[id].meta.ref != 1 {
@@ -164,9 +170,10 @@ func (s *RefCountedSet) Insert(newID uint64, value Hashable) ID {
 		// unless its ID is greater than the one we're
 		// given (i.e. prefer smaller IDs).
 		if item.meta.ref == 0 {
-			// Reap the dead item.
+			// Reap the dead item. Its ID is no longer in the table so it
+			// must not be deleted again later.
 			s.pslStats[item.meta.psl] -= 1
-			*item = elem{}
+			items[id] = nil
 
 			// Only resurrect this item if it has a
 			// smaller id than the one we were given.
@@ -194,6 +201,7 @@ func (s *RefCountedSet) Insert(newID uint64, value Hashable) ID {
 				item.meta.ref < heldItem.meta.ref) {
 			// Put our held item in the bucket.
 			table[p] = ID(heldID)
+			heldItem.meta.bucketID = p
 			s.pslStats[heldItem.meta.psl]++
 			s.maxPSL = max(s.maxPSL, heldItem.meta.psl)
 
@@ -218,6 +226,23 @@ func (s *RefCountedSet) Insert(newID uint64, value Hashable) ID {
 	return ID(chosenId)
 }
 
+// Double the capacity of the set. Living items keep their IDs so references
+// held by the callers stay valid, dead items are dropped.
+func (s *RefCountedSet) grow() {
+	old := s.items
+	s.items = make([]*elem, 2*max(len(old), 1))
+	s.table = make(map[uint64]ID, len(s.items))
+	s.pslStats = make([]int64, len(s.items)+1)
+	s.maxPSL = 0
+	for id, item := range old {
+		if item == nil || item.meta.ref == 0 {
+			continue
+		}
+		newID := s.Insert(uint64(id), item.data)
+		s.items[newID].meta.ref = item.meta.ref
+	}
+}
+
 // Delete an item, removing any references from the table, and freeing its ID
 // to be re-used.
 func (s *RefCountedSet) DeleteItem(id ID) {
@@ -283,6 +308,9 @@ func (s *RefCountedSet) Release(id ID) {
 func (s *RefCountedSet) Lookup(val Hashable) (ID, bool) {
 	table := s.table
 	items := s.items
+	if len(items) == 0 {
+		return 0, false
+	}
 
 	hash := val.Hash()
 
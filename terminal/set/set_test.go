@@ -3,6 +3,7 @@ package set
 import (
 	"testing"
 
+	"github.com/hnimtadd/termio/terminal/utils"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -196,6 +197,33 @@ func TestSet_AddAfterDeleteDoesNotLeak(t *testing.T) {
 	assert.NotEqual(t, id, id2)
 }
 
+func TestSet_GrowKeepsIDs(t *testing.T) {
+	set := NewRefCountedSet(Options{Cap: utils.PointerTo(uint64(4))})
+
+	ids := make(map[uint64]ID)
+	for val := range uint64(50) {
+		ids[val] = set.Add(newTestHashable(val))
+	}
+	assert.Equal(t, 50, set.Count())
+
+	for val, id := range ids {
+		foundID, found := set.Lookup(newTestHashable(val))
+		assert.True(t, found)
+		assert.Equal(t, id, foundID)
+		assert.Equal(t, newTestHashable(val), set.Get(id))
+	}
+
+	// Released items can be reused after growing.
+	for val := range uint64(25) {
+		set.Release(ids[val])
+	}
+	assert.Equal(t, 25, set.Count())
+	for val := uint64(100); val < 125; val++ {
+		set.Add(newTestHashable(val))
+	}
+	assert.Equal(t, 50, set.Count())
+}
+
 func newTestSet() *RefCountedSet {
 	return NewRefCountedSet(Options{
 		Cap: nil, // Use default capacity
@@ -63,6 +63,14 @@ const (
 	// Reset bg colors.
 	AttributeTypeResetBg
 
+	// Fg palette color, the 8 standard (30-37) and bright (90-97) colors
+	// are the first 16 entries of the 256 color palette (38;5).
+	AttributeTypePaletteFg
+	// Bg palette color (40-47, 100-107 and 48;5).
+	AttributeTypePaletteBg
+	// Underline palette color (58;5).
+	AttributeTypePaletteUnderlineColor
+
 	// Unkown
 	AttributeTypeUnknown
 )
@@ -84,12 +92,15 @@ type unknown struct {
 }
 
 type Attribute struct {
-	Type           AttributeType
-	Underline      UnderlineType
-	UnderlineColor color.RGB
-	Unknown        unknown
-	DirectColorFg  color.RGB
-	DirectColorBg  color.RGB
+	Type                  AttributeType
+	Underline             UnderlineType
+	UnderlineColor        color.RGB
+	Unknown               unknown
+	DirectColorFg         color.RGB
+	DirectColorBg         color.RGB
+	PaletteFg             uint8
+	PaletteBg             uint8
+	PaletteUnderlineColor uint8
 }
 type Parser struct {
 	Params    []uint16
@@ -121,7 +132,7 @@ func (p *Parser) next() func() (attr *Attribute, ok bool) {
 		if colon {
 			switch slice[0] {
 			// Underline, FG colored, BG colored is support, Set Underline colored
-			case 4, 38, 48:
+			case 4, 38, 48, 58:
 				// we need colon separated value for colors
 				break
 			default:
@@ -226,10 +237,9 @@ func (p *Parser) next() func() (attr *Attribute, ok bool) {
 			return &Attribute{Type: AttributeTypeResetStrikethrough}, true
 		// Standard ANSI foreground colors (30-37)
 		case 30, 31, 32, 33, 34, 35, 36, 37:
-			// For now, we don't have indexed color support, so treat as unknown but handled
 			return &Attribute{
-				Type:    AttributeTypeUnknown,
-				Unknown: unknown{Full: p.Params, Partial: slice},
+				Type:      AttributeTypePaletteFg,
+				PaletteFg: uint8(slice[0] - 30),
 			}, true
 		case 38:
 			if len(slice) >= 2 {
@@ -245,7 +255,15 @@ func (p *Parser) next() func() (attr *Attribute, ok bool) {
 					} else {
 						return nil, true
 					}
-				// case 5 we don't support indexed color yet.
+				// indexed color (n)
+				case 5:
+					if index, ok := p.parseIndexedColor(slice); ok {
+						return &Attribute{
+							Type:      AttributeTypePaletteFg,
+							PaletteFg: index,
+						}, true
+					}
+					return nil, true
 				default:
 					return nil, true
 				}
@@ -255,10 +273,9 @@ func (p *Parser) next() func() (attr *Attribute, ok bool) {
 			return &Attribute{Type: AttributeTypeResetFg}, true
 		// Standard ANSI background colors (40-47)
 		case 40, 41, 42, 43, 44, 45, 46, 47:
-			// For now, we don't have indexed color support, so treat as unknown but handled
 			return &Attribute{
-				Type:    AttributeTypeUnknown,
-				Unknown: unknown{Full: p.Params, Partial: slice},
+				Type:      AttributeTypePaletteBg,
+				PaletteBg: uint8(slice[0] - 40),
 			}, true
 		case 48:
 			if len(slice) >= 2 {
@@ -274,7 +291,15 @@ func (p *Parser) next() func() (attr *Attribute, ok bool) {
 					} else {
 						return nil, true
 					}
-				// case 5 we don't support indexed color yet.
+				// indexed color (n)
+				case 5:
+					if index, ok := p.parseIndexedColor(slice); ok {
+						return &Attribute{
+							Type:      AttributeTypePaletteBg,
+							PaletteBg: index,
+						}, true
+					}
+					return nil, true
 				default:
 					return nil, true
 				}
@@ -300,7 +325,15 @@ func (p *Parser) next() func() (attr *Attribute, ok bool) {
 					} else {
 						return nil, true
 					}
-				// case 5 we don't support indexed color yet.
+				// indexed color (n)
+				case 5:
+					if index, ok := p.parseIndexedColor(slice); ok {
+						return &Attribute{
+							Type:                  AttributeTypePaletteUnderlineColor,
+							PaletteUnderlineColor: index,
+						}, true
+					}
+					return nil, true
 				default:
 					return nil, true
 				}
@@ -309,17 +342,15 @@ func (p *Parser) next() func() (attr *Attribute, ok bool) {
 			return &Attribute{Type: AttributeTypeResetUnderlineColor}, true
 		// Bright/extended ANSI foreground colors (90-97)
 		case 90, 91, 92, 93, 94, 95, 96, 97:
-			// Bright colors - for now treat as unknown but handled
 			return &Attribute{
-				Type:    AttributeTypeUnknown,
-				Unknown: unknown{Full: p.Params, Partial: slice},
+				Type:      AttributeTypePaletteFg,
+				PaletteFg: uint8(slice[0]-90) + uint8(color.ColorTypeBrightBlack),
 			}, true
 		// Bright/extended ANSI background colors (100-107)
 		case 100, 101, 102, 103, 104, 105, 106, 107:
-			// Bright background colors - for now treat as unknown but handled
 			return &Attribute{
-				Type:    AttributeTypeUnknown,
-				Unknown: unknown{Full: p.Params, Partial: slice},
+				Type:      AttributeTypePaletteBg,
+				PaletteBg: uint8(slice[0]-100) + uint8(color.ColorTypeBrightBlack),
 			}, true
 		}
 		return &Attribute{
@@ -408,6 +439,23 @@ func (p *Parser) parseDirectColor(slice []uint16, colon bool) *color.RGB {
 	}
 }
 
+// parseIndexedColor parses the palette index of an indexed color style
+// (38;5;n, 48;5;n or 58;5;n), ok is false if the index is missing or out of
+// the 256 color palette.
+func (p *Parser) parseIndexedColor(slice []uint16) (index uint8, ok bool) {
+	// Assert this method only used for indexed color sets (38, 48, 58) and subparam 5.
+	utils.Assert(slice[1] == 5)
+	if len(slice) < 3 {
+		p.idx += 1
+		return 0, false
+	}
+	p.idx += 2
+	if slice[2] > math.MaxUint8 {
+		return 0, false
+	}
+	return uint8(slice[2]), true
+}
+
 // Returns true if the present position has a colon separator.
 // This always returns false for the last value since it has no
 // separator.
@@ -58,6 +58,78 @@ func TestParserNext(t *testing.T) {
 			paramsSep: utils.NewStaticBitSet(4),
 			expected:  nil,
 		},
+		{
+			name:      "[31]: palette fg",
+			params:    []uint16{31},
+			paramsSep: utils.NewStaticBitSet(1),
+			expected:  &Attribute{Type: AttributeTypePaletteFg, PaletteFg: 1},
+		},
+		{
+			name:      "[97]: bright palette fg",
+			params:    []uint16{97},
+			paramsSep: utils.NewStaticBitSet(1),
+			expected:  &Attribute{Type: AttributeTypePaletteFg, PaletteFg: 15},
+		},
+		{
+			name:      "[40]: palette bg",
+			params:    []uint16{40},
+			paramsSep: utils.NewStaticBitSet(1),
+			expected:  &Attribute{Type: AttributeTypePaletteBg, PaletteBg: 0},
+		},
+		{
+			name:      "[104]: bright palette bg",
+			params:    []uint16{104},
+			paramsSep: utils.NewStaticBitSet(1),
+			expected:  &Attribute{Type: AttributeTypePaletteBg, PaletteBg: 12},
+		},
+		{
+			name:      "[38, 5, 161]: indexed color fg",
+			params:    []uint16{38, 5, 161},
+			paramsSep: utils.NewStaticBitSet(3),
+			expected:  &Attribute{Type: AttributeTypePaletteFg, PaletteFg: 161},
+		},
+		{
+			name:      "[48, 5, 236]: indexed color bg",
+			params:    []uint16{48, 5, 236},
+			paramsSep: utils.NewStaticBitSet(3),
+			expected:  &Attribute{Type: AttributeTypePaletteBg, PaletteBg: 236},
+		},
+		{
+			name:      "[58, 5, 9]: indexed underline color",
+			params:    []uint16{58, 5, 9},
+			paramsSep: utils.NewStaticBitSet(3),
+			expected: &Attribute{
+				Type:                  AttributeTypePaletteUnderlineColor,
+				PaletteUnderlineColor: 9,
+			},
+		},
+		{
+			name:      "[38:5:161]: indexed color fg with colon",
+			params:    []uint16{38, 5, 161},
+			paramsSep: colonSep(3, 0, 1),
+			expected:  &Attribute{Type: AttributeTypePaletteFg, PaletteFg: 161},
+		},
+		{
+			name:      "[58:5:9]: indexed underline color with colon",
+			params:    []uint16{58, 5, 9},
+			paramsSep: colonSep(3, 0, 1),
+			expected: &Attribute{
+				Type:                  AttributeTypePaletteUnderlineColor,
+				PaletteUnderlineColor: 9,
+			},
+		},
+		{
+			name:      "[38, 5, 256]: out of range",
+			params:    []uint16{38, 5, 256},
+			paramsSep: utils.NewStaticBitSet(3),
+			expected:  nil,
+		},
+		{
+			name:      "[48, 5]: missing index",
+			params:    []uint16{48, 5},
+			paramsSep: utils.NewStaticBitSet(2),
+			expected:  nil,
+		},
 	}
 
 	for _, tc := range tests {
@@ -104,6 +176,27 @@ func TestParserNextMultiple(t *testing.T) {
 	})
 }
 
+func TestParserNextIndexedColors(t *testing.T) {
+	// Indexed colors consume their sub parameters, what comes after is
+	// parsed as a separate attribute.
+	parser := Parser{
+		Params:    []uint16{38, 5, 200, 1, 48, 5, 17, 91},
+		ParamsSep: utils.NewStaticBitSet(8),
+	}
+	var got []Attribute
+	for attr := range parser.Iter() {
+		if attr != nil {
+			got = append(got, *attr)
+		}
+	}
+	assert.Equal(t, []Attribute{
+		{Type: AttributeTypePaletteFg, PaletteFg: 200},
+		{Type: AttributeTypeBold},
+		{Type: AttributeTypePaletteBg, PaletteBg: 17},
+		{Type: AttributeTypePaletteFg, PaletteFg: 9},
+	}, got)
+}
+
 func TestUnsupportedWithColon(t *testing.T) {
 	t.Run("sgr: unsupported with colon", func(t *testing.T) {
 		sepList := utils.NewStaticBitSet(3)
@@ -236,3 +329,13 @@ func TestParserWithSingleAttribute(t *testing.T) {
 		})
 	}
 }
+
+// colonSep returns a separator set of the given size where the parameters
+// at the given indexes are followed by a colon.
+func colonSep(size int, idxs ...int) *utils.StaticBitSet {
+	sep := utils.NewStaticBitSet(size)
+	for _, idx := range idxs {
+		sep.Set(idx)
+	}
+	return sep
+}
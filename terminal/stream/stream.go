@@ -5,8 +5,10 @@ import (
 
 	"github.com/hnimtadd/termio/logger"
 	"github.com/hnimtadd/termio/terminal/ansi"
+	"github.com/hnimtadd/termio/terminal/charsets"
 	"github.com/hnimtadd/termio/terminal/core"
 	"github.com/hnimtadd/termio/terminal/handler"
+	"github.com/hnimtadd/termio/terminal/kitty"
 	"github.com/hnimtadd/termio/terminal/parser"
 	"github.com/hnimtadd/termio/terminal/sequences/csi"
 	"github.com/hnimtadd/termio/terminal/sequences/dcs"
@@ -77,7 +79,7 @@ func (s *Stream) NextSlice(input []uint8) {
 }
 
 func (s *Stream) nextSliceCapped(input []uint8, cpBuf []uint32) {
-	utils.Assert(len(input) < len(cpBuf))
+	utils.Assert(len(input) <= len(cpBuf))
 	offset := 0
 
 	for s.utf8Decoder.state != 0 {
@@ -302,9 +304,22 @@ func (s *Stream) execute(c uint8) {
 		// Bell character - silently ignore it (some terminals beep, but we'll just ignore)
 		return
 
+	// SO/SI - Shift Out/Shift In, invoke G1/G0 into GL
+	case c0.SO, c0.SI:
+		handler, implemented := s.handler.(handler.CharsetHandler)
+		if !implemented {
+			s.logger.Warn("unimplemented execute", "codepoint", c)
+			return
+		}
+		slot := charsets.SlotG0
+		if c == c0.SO {
+			slot = charsets.SlotG1
+		}
+		handler.InvokeCharset(charsets.ActiveSlotGL, slot, false)
+
 	// KAI do not support these characters as the moment, just put them here
 	// as a TODO for later enhancement.
-	case c0.NUL, c0.ENQ, c0.SO, c0.SI:
+	case c0.NUL, c0.ENQ:
 		s.logger.Warn("unimplemented characters, ignoring", "codepoint", c)
 		return
 
@@ -508,6 +523,78 @@ func (s *Stream) csiDispatch(c *csi.Command) {
 			s.logger.Warn("unimplemented CSI G with intermediates", "codepoint", c)
 			return
 		}
+	case 'a':
+		// HPR - Character Position Relative
+		switch len(c.Intermediates) {
+		case 0:
+			handler, implemented := s.handler.(handler.EditorHandler)
+			if !implemented {
+				s.logger.Warn("unimplemented HPR command", "codepoint", c)
+				return
+			}
+			var offset uint16
+			switch len(c.Params) {
+			case 0:
+				offset = 1
+			case 1:
+				offset = c.Params[0]
+			default:
+				s.logger.Warn("invalid HPR command", "codepoint", c)
+				return
+			}
+			handler.SetCursorColRelative(offset)
+		default:
+			s.logger.Warn("unimplemented CSI a with intermediates", "codepoint", c)
+			return
+		}
+	case 'd':
+		// VPA - Line Position Absolute
+		switch len(c.Intermediates) {
+		case 0:
+			handler, implemented := s.handler.(handler.EditorHandler)
+			if !implemented {
+				s.logger.Warn("unimplemented VPA command", "codepoint", c)
+				return
+			}
+			var row uint16
+			switch len(c.Params) {
+			case 0:
+				row = 1
+			case 1:
+				row = c.Params[0]
+			default:
+				s.logger.Warn("invalid VPA command", "codepoint", c)
+				return
+			}
+			handler.SetCursorRow(row)
+		default:
+			s.logger.Warn("unimplemented CSI d with intermediates", "codepoint", c)
+			return
+		}
+	case 'e':
+		// VPR - Line Position Relative
+		switch len(c.Intermediates) {
+		case 0:
+			handler, implemented := s.handler.(handler.EditorHandler)
+			if !implemented {
+				s.logger.Warn("unimplemented VPR command", "codepoint", c)
+				return
+			}
+			var offset uint16
+			switch len(c.Params) {
+			case 0:
+				offset = 1
+			case 1:
+				offset = c.Params[0]
+			default:
+				s.logger.Warn("invalid VPR command", "codepoint", c)
+				return
+			}
+			handler.SetCursorRowRelative(offset)
+		default:
+			s.logger.Warn("unimplemented CSI e with intermediates", "codepoint", c)
+			return
+		}
 	case 'H', 'f':
 		// CUP - Cursor Position
 		// HVP - Horizontal Vertical Position
@@ -542,6 +629,7 @@ func (s *Stream) csiDispatch(c *csi.Command) {
 			handler, implemented := s.handler.(handler.EditorHandler)
 			if !implemented {
 				s.logger.Warn("unimplemented CHT command", "codepoint", c)
+				return
 			}
 			var numTab uint16
 			switch len(c.Params) {
@@ -648,9 +736,10 @@ func (s *Stream) csiDispatch(c *csi.Command) {
 			case 0:
 				repeated = 1
 			case 1:
-				repeated = c.Params[1]
+				repeated = c.Params[0]
 			default:
 				s.logger.Warn("invalid DL command", "codepoint", c)
+				return
 			}
 			handler.DeleteLines(repeated)
 		default:
@@ -671,9 +760,10 @@ func (s *Stream) csiDispatch(c *csi.Command) {
 			case 0:
 				repeated = 1
 			case 1:
-				repeated = c.Params[1]
+				repeated = c.Params[0]
 			default:
 				s.logger.Warn("invalid DCH command", "codepoint", c)
+				return
 			}
 			handler.DeleteChars(repeated)
 		default:
@@ -681,7 +771,114 @@ func (s *Stream) csiDispatch(c *csi.Command) {
 		}
 
 	case 'S':
-		// SD - Scroll Up
+		// SU - Scroll Up
+		switch len(c.Intermediates) {
+		case 0:
+			handler, implemented := s.handler.(handler.EditorHandler)
+			if !implemented {
+				s.logger.Warn("unimplemented SU command", "codepoint", c)
+				return
+			}
+			var repeated uint16
+			switch len(c.Params) {
+			case 0:
+				repeated = 1
+			case 1:
+				repeated = c.Params[0]
+			default:
+				s.logger.Warn("invalid SU command", "codepoint", c)
+				return
+			}
+			handler.ScrollUp(repeated)
+		default:
+			s.logger.Warn("unimplemented CSI S with intermediates", "codepoint", c)
+		}
+
+	case 'T':
+		// SD - Scroll Down
+		switch len(c.Intermediates) {
+		case 0:
+			handler, implemented := s.handler.(handler.EditorHandler)
+			if !implemented {
+				s.logger.Warn("unimplemented SD command", "codepoint", c)
+				return
+			}
+			var repeated uint16
+			switch len(c.Params) {
+			case 0:
+				repeated = 1
+			case 1:
+				repeated = c.Params[0]
+			default:
+				// CSI Ps ; Ps ; Ps ; Ps ; Ps T is the xterm mouse
+				// highlight tracking, which we don't support.
+				s.logger.Warn("invalid SD command", "codepoint", c)
+				return
+			}
+			handler.ScrollDown(repeated)
+		default:
+			s.logger.Warn("unimplemented CSI T with intermediates", "codepoint", c)
+		}
+
+	case 'X':
+		// ECH - Erase Characters
+		switch len(c.Intermediates) {
+		case 0:
+			handler, implemented := s.handler.(handler.EditorHandler)
+			if !implemented {
+				s.logger.Warn("unimplemented ECH command", "codepoint", c)
+				return
+			}
+			var repeated uint16
+			switch len(c.Params) {
+			case 0:
+				repeated = 1
+			case 1:
+				repeated = c.Params[0]
+			default:
+				s.logger.Warn("invalid ECH command", "codepoint", c)
+				return
+			}
+			handler.EraseChars(repeated)
+		default:
+			s.logger.Warn("unimplemented CSI X with intermediates", "codepoint", c)
+		}
+
+	case 't':
+		// XTWINOPS - Window manipulation
+		switch len(c.Intermediates) {
+		case 0:
+			handler, implemented := s.handler.(handler.WindowOperationHandler)
+			if !implemented {
+				s.logger.Warn("unimplemented XTWINOPS command", "codepoint", c)
+				return
+			}
+			if len(c.Params) == 0 || len(c.Params) > 3 {
+				s.logger.Warn("invalid XTWINOPS command", "codepoint", c)
+				return
+			}
+			// The second parameter of the title stack operations selects
+			// the target: 0 both, 1 icon name, 2 window title.
+			target := osc.TitleTargetBoth
+			if len(c.Params) >= 2 {
+				if c.Params[1] > uint16(osc.TitleTargetWindow) {
+					s.logger.Warn("invalid XTWINOPS command", "codepoint", c)
+					return
+				}
+				target = osc.TitleTarget(c.Params[1])
+			}
+			switch c.Params[0] {
+			case 22:
+				handler.PushTitle(target)
+			case 23:
+				handler.PopTitle(target)
+			default:
+				s.logger.Warn("unimplemented XTWINOPS command", "codepoint", c)
+			}
+		default:
+			s.logger.Warn("unimplemented CSI t with intermediates", "codepoint", c)
+			return
+		}
 
 	case 'm':
 		// SGR - Select Graphic Rendition
@@ -701,6 +898,39 @@ func (s *Stream) csiDispatch(c *csi.Command) {
 					handler.SetGraphicsRendition(attr)
 				}
 			}
+		case 1:
+			if c.Intermediates[0] != '>' {
+				s.logger.Warn("unimplemented CSI m with intermediates", "codepoint", c)
+				return
+			}
+			// XTMODKEYS - Set/reset key modifier options, we only support
+			// modifyOtherKeys (4)
+			handler, implemented := s.handler.(handler.KeyboardHandler)
+			if !implemented {
+				s.logger.Warn("unimplemented XTMODKEYS command", "codepoint", c)
+				return
+			}
+			switch len(c.Params) {
+			case 0:
+				// Resets all the options
+				handler.SetModifyOtherKeys(core.ModifyOtherKeysNone)
+			case 1, 2:
+				if c.Params[0] != 4 {
+					s.logger.Warn("unimplemented XTMODKEYS resource", "codepoint", c)
+					return
+				}
+				level := core.ModifyOtherKeysNone
+				if len(c.Params) == 2 {
+					if c.Params[1] > uint16(core.ModifyOtherKeysAll) {
+						s.logger.Warn("invalid XTMODKEYS command", "codepoint", c)
+						return
+					}
+					level = core.ModifyOtherKeys(c.Params[1])
+				}
+				handler.SetModifyOtherKeys(level)
+			default:
+				s.logger.Warn("invalid XTMODKEYS command", "codepoint", c)
+			}
 		default:
 			s.logger.Warn("unimplemented CSI m with intermediates", "codepoint", c)
 			return
@@ -721,8 +951,10 @@ func (s *Stream) csiDispatch(c *csi.Command) {
 			ansiMode = false
 		default:
 			s.logger.Warn("invalid set mode command", "codepoint", c)
+			return
 		}
-		for modeInt := range c.Params {
+		for _, param := range c.Params {
+			modeInt := int(param)
 			if mode := core.ModeFromInt(modeInt, ansiMode); mode != nil {
 				handler.SetMode(*mode, true)
 			} else {
@@ -748,8 +980,10 @@ func (s *Stream) csiDispatch(c *csi.Command) {
 			ansiMode = false
 		default:
 			s.logger.Warn("invalid reset mode command", "codepoint", c)
+			return
 		}
-		for modeInt := range c.Params {
+		for _, param := range c.Params {
+			modeInt := int(param)
 			if mode := core.ModeFromInt(modeInt, ansiMode); mode != nil {
 				handler.SetMode(*mode, false)
 			} else {
@@ -759,6 +993,93 @@ func (s *Stream) csiDispatch(c *csi.Command) {
 				}
 			}
 		}
+	case 'g':
+		// TBC - Tabulation Clear
+		switch len(c.Intermediates) {
+		case 0:
+			handler, implemented := s.handler.(handler.FormatEffectorHandler)
+			if !implemented {
+				s.logger.Warn("unimplemented TBC command", "codepoint", c)
+				return
+			}
+			mode := csi.TBCModeCurrent
+			switch len(c.Params) {
+			case 0:
+			case 1:
+				mode = csi.TBCMode(c.Params[0])
+			default:
+				s.logger.Warn("invalid TBC command", "codepoint", c)
+				return
+			}
+			handler.TabClear(mode)
+		default:
+			s.logger.Warn("unimplemented CSI g with intermediates", "codepoint", c)
+		}
+
+	case 'Z':
+		// CBT - Cursor Backward Tabulation
+		switch len(c.Intermediates) {
+		case 0:
+			handler, implemented := s.handler.(handler.EditorHandler)
+			if !implemented {
+				s.logger.Warn("unimplemented CBT command", "codepoint", c)
+				return
+			}
+			var numTab uint16
+			switch len(c.Params) {
+			case 0:
+				numTab = 1
+			case 1:
+				numTab = c.Params[0]
+			default:
+				s.logger.Warn("invalid CBT command", "codepoint", c)
+				return
+			}
+			handler.SetCursorTabLeft(numTab)
+		default:
+			s.logger.Warn("unimplemented CSI Z with intermediates", "codepoint", c)
+		}
+
+	case 'W':
+		// DECST8C - Set Tab at Every 8 Columns
+		if len(c.Intermediates) != 1 || c.Intermediates[0] != '?' {
+			s.logger.Warn("unimplemented CSI W", "codepoint", c)
+			return
+		}
+		handler, implemented := s.handler.(handler.FormatEffectorHandler)
+		if !implemented {
+			s.logger.Warn("unimplemented DECST8C command", "codepoint", c)
+			return
+		}
+		if len(c.Params) != 1 || c.Params[0] != 5 {
+			s.logger.Warn("invalid DECST8C command", "codepoint", c)
+			return
+		}
+		handler.TabReset()
+
+	case 'w':
+		// DECRQPSR - Request Presentation State Report
+		if len(c.Intermediates) != 1 || c.Intermediates[0] != '$' {
+			s.logger.Warn("unimplemented CSI w", "codepoint", c)
+			return
+		}
+		handler, implemented := s.handler.(handler.DeviceReportHandler)
+		if !implemented {
+			s.logger.Warn("unimplemented DECRQPSR command", "codepoint", c)
+			return
+		}
+		if len(c.Params) != 1 {
+			s.logger.Warn("invalid DECRQPSR command", "codepoint", c)
+			return
+		}
+		switch c.Params[0] {
+		case 2:
+			handler.TabStopReport()
+		default:
+			// 1 is the cursor information report (DECCIR).
+			s.logger.Warn("unimplemented DECRQPSR report", "codepoint", c)
+		}
+
 	case 'q':
 		// DECSCUSR - Set Cursor Style
 		switch len(c.Intermediates) {
@@ -778,17 +1099,25 @@ func (s *Stream) csiDispatch(c *csi.Command) {
 		case 1:
 			// DECSCUSR with space intermediate (ESC [ Pn SP q)
 			if len(c.Intermediates) == 1 && c.Intermediates[0] == 0x20 {
-				var style uint16
+				handler, implemented := s.handler.(handler.CursorStyleHandler)
+				if !implemented {
+					s.logger.Warn("unimplemented DECSCUSR command", "codepoint", c)
+					return
+				}
+				style := csi.CursorStyleDefault
 				switch len(c.Params) {
 				case 0:
-					style = 1 // Default cursor style
 				case 1:
-					style = c.Params[0]
+					if c.Params[0] > uint16(csi.CursorStyleSteadyBar) {
+						s.logger.Warn("invalid DECSCUSR command", "codepoint", c)
+						return
+					}
+					style = csi.CursorStyle(c.Params[0])
 				default:
 					s.logger.Warn("invalid DECSCUSR command", "codepoint", c)
 					return
 				}
-				s.logger.Debug("cursor style command with space", "style", style)
+				handler.SetCursorStyle(style)
 			} else {
 				s.logger.Warn("unimplemented CSI q with intermediates", "codepoint", c)
 				return
@@ -797,6 +1126,280 @@ func (s *Stream) csiDispatch(c *csi.Command) {
 			s.logger.Warn("unimplemented CSI q with intermediates", "codepoint", c)
 			return
 		}
+	case 'b':
+		// REP - Repeat the previous printed character
+		switch len(c.Intermediates) {
+		case 0:
+			handler, implemented := s.handler.(handler.PrintHandler)
+			if !implemented {
+				s.logger.Warn("unimplemented REP command", "codepoint", c)
+				return
+			}
+			var repeated uint16
+			switch len(c.Params) {
+			case 0:
+				repeated = 1
+			case 1:
+				repeated = c.Params[0]
+			default:
+				s.logger.Warn("invalid REP command", "codepoint", c)
+				return
+			}
+			handler.PrintRepeat(repeated)
+		default:
+			s.logger.Warn("unimplemented CSI b with intermediates", "codepoint", c)
+		}
+
+	case 'c':
+		// DA - Device Attributes
+		handler, implemented := s.handler.(handler.DeviceReportHandler)
+		if !implemented {
+			s.logger.Warn("unimplemented DA command", "codepoint", c)
+			return
+		}
+		// Only the default parameter 0 is defined.
+		if len(c.Params) > 1 || (len(c.Params) == 1 && c.Params[0] != 0) {
+			s.logger.Warn("invalid DA command", "codepoint", c)
+			return
+		}
+		switch {
+		case len(c.Intermediates) == 0:
+			handler.DeviceAttributes(csi.DARequestPrimary)
+		case len(c.Intermediates) == 1 && c.Intermediates[0] == '>':
+			handler.DeviceAttributes(csi.DARequestSecondary)
+		case len(c.Intermediates) == 1 && c.Intermediates[0] == '=':
+			handler.DeviceAttributes(csi.DARequestTertiary)
+		default:
+			s.logger.Warn("invalid DA command", "codepoint", c)
+		}
+
+	case 'n':
+		// DSR - Device Status Report
+		handler, implemented := s.handler.(handler.DeviceReportHandler)
+		if !implemented {
+			s.logger.Warn("unimplemented DSR command", "codepoint", c)
+			return
+		}
+		if len(c.Params) != 1 {
+			s.logger.Warn("invalid DSR command", "codepoint", c)
+			return
+		}
+		switch {
+		case len(c.Intermediates) == 0:
+			switch c.Params[0] {
+			case 5:
+				handler.DeviceStatusReport(csi.DSRRequestOperatingStatus)
+			case 6:
+				handler.DeviceStatusReport(csi.DSRRequestCursorPosition)
+			default:
+				s.logger.Warn("unimplemented DSR command", "codepoint", c)
+			}
+		case len(c.Intermediates) == 1 && c.Intermediates[0] == '?':
+			switch c.Params[0] {
+			case 6:
+				handler.DeviceStatusReport(csi.DSRRequestExtendedCursorPosition)
+			default:
+				s.logger.Warn("unimplemented DECDSR command", "codepoint", c)
+			}
+		default:
+			s.logger.Warn("invalid DSR command", "codepoint", c)
+		}
+
+	case 'p':
+		if len(c.Intermediates) == 1 && c.Intermediates[0] == '!' {
+			// DECSTR - Soft Terminal Reset
+			handler, implemented := s.handler.(handler.FormatEffectorHandler)
+			if !implemented {
+				s.logger.Warn("unimplemented DECSTR command", "codepoint", c)
+				return
+			}
+			if len(c.Params) != 0 {
+				s.logger.Warn("invalid DECSTR command", "codepoint", c)
+				return
+			}
+			handler.SoftReset()
+			return
+		}
+
+		// DECRQM - Request Mode
+		var ansiMode bool
+		switch {
+		case len(c.Intermediates) == 1 && c.Intermediates[0] == '$':
+			ansiMode = true
+		case len(c.Intermediates) == 2 && c.Intermediates[0] == '?' && c.Intermediates[1] == '$':
+			ansiMode = false
+		default:
+			s.logger.Warn("unimplemented CSI p command", "codepoint", c)
+			return
+		}
+		handler, implemented := s.handler.(handler.DeviceReportHandler)
+		if !implemented {
+			s.logger.Warn("unimplemented DECRQM command", "codepoint", c)
+			return
+		}
+		if len(c.Params) != 1 {
+			s.logger.Warn("invalid DECRQM command", "codepoint", c)
+			return
+		}
+		handler.RequestMode(int(c.Params[0]), ansiMode)
+
+	case 'r':
+		// DECSTBM - Set Top and Bottom Margins
+		switch len(c.Intermediates) {
+		case 0:
+			handler, implemented := s.handler.(handler.MarginHandler)
+			if !implemented {
+				s.logger.Warn("unimplemented DECSTBM command", "codepoint", c)
+				return
+			}
+			switch len(c.Params) {
+			case 0:
+				handler.SetTopAndBottomMargin(0, 0)
+			case 1:
+				handler.SetTopAndBottomMargin(c.Params[0], 0)
+			case 2:
+				handler.SetTopAndBottomMargin(c.Params[0], c.Params[1])
+			default:
+				s.logger.Warn("invalid DECSTBM command", "codepoint", c)
+			}
+		case 1:
+			if c.Intermediates[0] != '?' {
+				s.logger.Warn("unimplemented CSI r with intermediates", "codepoint", c)
+				return
+			}
+			// XTRESTORE - Restore DEC Private Mode Values
+			handler, implemented := s.handler.(handler.VT100Handler)
+			if !implemented {
+				s.logger.Warn("unimplemented XTRESTORE command", "codepoint", c)
+				return
+			}
+			for _, param := range c.Params {
+				if mode := core.ModeFromInt(int(param), false); mode != nil {
+					handler.RestoreMode(*mode)
+				} else {
+					s.logger.Warn("unimplemented mode", "mode", param)
+				}
+			}
+		default:
+			s.logger.Warn("unimplemented CSI r with intermediates", "codepoint", c)
+		}
+
+	case 's':
+		// DECSLRM - Set Left and Right Margins
+		switch len(c.Intermediates) {
+		case 0:
+			handler, implemented := s.handler.(handler.MarginHandler)
+			if !implemented {
+				s.logger.Warn("unimplemented DECSLRM command", "codepoint", c)
+				return
+			}
+			switch len(c.Params) {
+			case 0:
+				// Without parameters this is also SCOSC (save cursor), the
+				// handler decides based on the DECLRMM mode.
+				handler.SetLeftAndRightMarginAmbiguous()
+			case 1:
+				handler.SetLeftAndRightMargin(c.Params[0], 0)
+			case 2:
+				handler.SetLeftAndRightMargin(c.Params[0], c.Params[1])
+			default:
+				s.logger.Warn("invalid DECSLRM command", "codepoint", c)
+			}
+		case 1:
+			if c.Intermediates[0] != '?' {
+				s.logger.Warn("unimplemented CSI s with intermediates", "codepoint", c)
+				return
+			}
+			// XTSAVE - Save DEC Private Mode Values
+			handler, implemented := s.handler.(handler.VT100Handler)
+			if !implemented {
+				s.logger.Warn("unimplemented XTSAVE command", "codepoint", c)
+				return
+			}
+			for _, param := range c.Params {
+				if mode := core.ModeFromInt(int(param), false); mode != nil {
+					handler.SaveMode(*mode)
+				} else {
+					s.logger.Warn("unimplemented mode", "mode", param)
+				}
+			}
+		default:
+			s.logger.Warn("unimplemented CSI s with intermediates", "codepoint", c)
+		}
+
+	case 'u':
+		// SCORC - Restore Cursor
+		switch len(c.Intermediates) {
+		case 0:
+			handler, implemented := s.handler.(handler.CursorStateHandler)
+			if !implemented {
+				s.logger.Warn("unimplemented SCORC command", "codepoint", c)
+				return
+			}
+			if len(c.Params) != 0 {
+				s.logger.Warn("invalid SCORC command", "codepoint", c)
+				return
+			}
+			handler.RestoreCursor()
+		case 1:
+			// Kitty keyboard protocol
+			handler, implemented := s.handler.(handler.KeyboardHandler)
+			if !implemented {
+				s.logger.Warn("unimplemented kitty keyboard command", "codepoint", c)
+				return
+			}
+			switch c.Intermediates[0] {
+			case '?':
+				handler.QueryKittyKeyboard()
+			case '>':
+				var flags uint16
+				switch len(c.Params) {
+				case 0:
+				case 1:
+					flags = c.Params[0]
+				default:
+					s.logger.Warn("invalid kitty keyboard push command", "codepoint", c)
+					return
+				}
+				handler.PushKittyKeyboard(kitty.KeyFlags(flags) & kitty.KeyFlagsAll)
+			case '<':
+				var n uint16
+				switch len(c.Params) {
+				case 0:
+					n = 1
+				case 1:
+					n = max(c.Params[0], 1)
+				default:
+					s.logger.Warn("invalid kitty keyboard pop command", "codepoint", c)
+					return
+				}
+				handler.PopKittyKeyboard(n)
+			case '=':
+				var flags uint16
+				mode := kitty.KeySetModeSet
+				switch len(c.Params) {
+				case 0:
+				case 1:
+					flags = c.Params[0]
+				case 2:
+					flags = c.Params[0]
+					mode = kitty.KeySetMode(c.Params[1])
+				default:
+					s.logger.Warn("invalid kitty keyboard set command", "codepoint", c)
+					return
+				}
+				if mode < kitty.KeySetModeSet || mode > kitty.KeySetModeNot {
+					s.logger.Warn("invalid kitty keyboard set command", "codepoint", c)
+					return
+				}
+				handler.SetKittyKeyboard(mode, kitty.KeyFlags(flags)&kitty.KeyFlagsAll)
+			default:
+				s.logger.Warn("unimplemented CSI u with intermediates", "codepoint", c)
+			}
+		default:
+			s.logger.Warn("unimplemented CSI u with intermediates", "codepoint", c)
+		}
+
 	case '@':
 		// ICH - Insert Blanks
 		handler, implemented := s.handler.(handler.EditorHandler)
@@ -895,6 +1498,113 @@ func (s *Stream) escDispatch(c *esc.Command) {
 			s.logger.Warn("invalid RIS command", "codepoint", c)
 			return
 		}
+	case '7':
+		// DECSC - Save Cursor
+		handler, implemented := s.handler.(handler.CursorStateHandler)
+		if !implemented {
+			s.logger.Warn("unimplemented DECSC command", "codepoint", c)
+			return
+		}
+		switch len(c.Intermediates) {
+		case 0:
+			handler.SaveCursor()
+		default:
+			s.logger.Warn("invalid DECSC command", "codepoint", c)
+			return
+		}
+
+	case '8':
+		// DECRC - Restore Cursor
+		handler, implemented := s.handler.(handler.CursorStateHandler)
+		if !implemented {
+			s.logger.Warn("unimplemented DECRC command", "codepoint", c)
+			return
+		}
+		switch len(c.Intermediates) {
+		case 0:
+			handler.RestoreCursor()
+		default:
+			// ESC # 8 is DECALN (screen alignment test)
+			s.logger.Warn("unimplemented ESC 8 with intermediates", "codepoint", c)
+			return
+		}
+
+	case 'B', 'A', '0':
+		// SCS - Select Character Set
+		handler, implemented := s.handler.(handler.CharsetHandler)
+		if !implemented {
+			s.logger.Warn("unimplemented SCS command", "codepoint", c)
+			return
+		}
+		if len(c.Intermediates) != 1 {
+			s.logger.Warn("invalid SCS command", "codepoint", c)
+			return
+		}
+		var slot charsets.Slot
+		switch c.Intermediates[0] {
+		case '(':
+			slot = charsets.SlotG0
+		case ')':
+			slot = charsets.SlotG1
+		case '*':
+			slot = charsets.SlotG2
+		case '+':
+			slot = charsets.SlotG3
+		default:
+			s.logger.Warn("invalid SCS command", "codepoint", c)
+			return
+		}
+		set := charsets.CharsetASCII
+		switch c.Final {
+		case 'A':
+			set = charsets.CharsetBritish
+		case '0':
+			set = charsets.CharsetDECSpecial
+		}
+		handler.ConfigureCharset(slot, set)
+
+	case 'N', 'O', 'n', 'o', '~', '}', '|':
+		// SS2/SS3 - Single Shift, LS2/LS3 - Locking Shift into GL,
+		// LS1R/LS2R/LS3R - Locking Shift into GR
+		handler, implemented := s.handler.(handler.CharsetHandler)
+		if !implemented {
+			s.logger.Warn("unimplemented locking shift command", "codepoint", c)
+			return
+		}
+		if len(c.Intermediates) != 0 {
+			s.logger.Warn("invalid locking shift command", "codepoint", c)
+			return
+		}
+		switch c.Final {
+		case 'N':
+			handler.InvokeCharset(charsets.ActiveSlotGL, charsets.SlotG2, true)
+		case 'O':
+			handler.InvokeCharset(charsets.ActiveSlotGL, charsets.SlotG3, true)
+		case 'n':
+			handler.InvokeCharset(charsets.ActiveSlotGL, charsets.SlotG2, false)
+		case 'o':
+			handler.InvokeCharset(charsets.ActiveSlotGL, charsets.SlotG3, false)
+		case '~':
+			handler.InvokeCharset(charsets.ActiveSlotGR, charsets.SlotG1, false)
+		case '}':
+			handler.InvokeCharset(charsets.ActiveSlotGR, charsets.SlotG2, false)
+		case '|':
+			handler.InvokeCharset(charsets.ActiveSlotGR, charsets.SlotG3, false)
+		}
+
+	case '=', '>':
+		// DECKPAM - Keypad Application Mode, DECKPNM - Keypad Numeric Mode
+		handler, implemented := s.handler.(handler.VT100Handler)
+		if !implemented {
+			s.logger.Warn("unimplemented DECKPAM/DECKPNM command", "codepoint", c)
+			return
+		}
+		if len(c.Intermediates) != 0 {
+			s.logger.Warn("invalid DECKPAM/DECKPNM command", "codepoint", c)
+			return
+		}
+		handler.SetMode(core.ModeKeypadKeys, c.Final == '=')
+
 	case '\\':
 		// ST - String terminator
 		//  We don't have to do anything.
@@ -905,8 +1615,99 @@ func (s *Stream) escDispatch(c *esc.Command) {
 //
 // not all VT100 control sequences supported by KAI,
 // escecially VT100 to Host control sequences
-func (s *Stream) oscDispatch(osc *osc.Command) {
-	s.logger.Warn("unimplemented osc dispatch", "command", osc)
+func (s *Stream) oscDispatch(cmd *osc.Command) {
+	switch cmd.Type {
+	case osc.CommandTypeChangeWindowTitle:
+		handler, implemented := s.handler.(handler.WindowTitleHandler)
+		if !implemented {
+			s.logger.Warn("unimplemented OSC change window title command", "command", cmd)
+			return
+		}
+		handler.ChangeWindowTitle(cmd.WindowTitleData.Target, cmd.WindowTitleData.Title)
+
+	case osc.CommandTypeReportPwd:
+		handler, implemented := s.handler.(handler.ReportPwdHandler)
+		if !implemented {
+			s.logger.Warn("unimplemented OSC report pwd command", "command", cmd)
+			return
+		}
+		handler.ReportPwd(cmd.ReportPwdData)
+
+	case osc.CommandTypeHyperlinkStart:
+		handler, implemented := s.handler.(handler.HyperlinkHandler)
+		if !implemented {
+			s.logger.Warn("unimplemented OSC hyperlink start command", "command", cmd)
+			return
+		}
+		handler.StartHyperlink(cmd.HyperlinkStartData.URI, cmd.HyperlinkStartData.ID)
+
+	case osc.CommandTypeHyperlinkEnd:
+		handler, implemented := s.handler.(handler.HyperlinkHandler)
+		if !implemented {
+			s.logger.Warn("unimplemented OSC hyperlink end command", "command", cmd)
+			return
+		}
+		handler.EndHyperlink()
+
+	case osc.CommandTypeColorOperation:
+		handler, implemented := s.handler.(handler.ColorOperationHandler)
+		if !implemented {
+			s.logger.Warn("unimplemented OSC color operation command", "command", cmd)
+			return
+		}
+		handler.ColorOperation(cmd.ColorOperationData, cmd.Terminator)
+
+	case osc.CommandTypeClipboardContents:
+		handler, implemented := s.handler.(handler.ClipboardHandler)
+		if !implemented {
+			s.logger.Warn("unimplemented OSC clipboard command", "command", cmd)
+			return
+		}
+		handler.ClipboardContents(cmd.ClipboardData.Kind, cmd.ClipboardData.Data, cmd.Terminator)
+
+	case osc.CommandTypePromptStart:
+		handler, implemented := s.handler.(handler.SemanticPromptHandler)
+		if !implemented {
+			s.logger.Warn("unimplemented OSC prompt start command", "command", cmd)
+			return
+		}
+		handler.PromptStart(*cmd.PromptStartData)
+
+	case osc.CommandTypePromptEnd:
+		handler, implemented := s.handler.(handler.SemanticPromptHandler)
+		if !implemented {
+			s.logger.Warn("unimplemented OSC prompt end command", "command", cmd)
+			return
+		}
+		handler.PromptEnd()
+
+	case osc.CommandTypeEndOfInput:
+		handler, implemented := s.handler.(handler.SemanticPromptHandler)
+		if !implemented {
+			s.logger.Warn("unimplemented OSC end of input command", "command", cmd)
+			return
+		}
+		handler.EndOfInput()
+
+	case osc.CommandTypeEndOfCommand:
+		handler, implemented := s.handler.(handler.SemanticPromptHandler)
+		if !implemented {
+			s.logger.Warn("unimplemented OSC end of command command", "command", cmd)
+			return
+		}
+		handler.EndOfCommand(*cmd.EndOfCommandData)
+
+	case osc.CommandTypeShowDesktopNotification:
+		handler, implemented := s.handler.(handler.NotificationHandler)
+		if !implemented {
+			s.logger.Warn("unimplemented OSC desktop notification command", "command", cmd)
+			return
+		}
+		handler.ShowDesktopNotification(cmd.NotificationData.Title, cmd.NotificationData.Body)
+
+	default:
+		s.logger.Warn("unimplemented OSC command", "command", cmd)
+	}
 }
 
 // consumeUntilGround read the stream until we got the ground state
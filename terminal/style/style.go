@@ -157,7 +157,7 @@ func (s Style) Delete() {
 }
 
 // ToANSI generates ANSI escape sequence for this style
-func (s *Style) ToANSI() string {
+func (s Style) ToANSI() string {
 	var codes []string
 	
 	// Handle bold
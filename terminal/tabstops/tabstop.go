@@ -118,6 +118,17 @@ func (t *Tabstops) Resize(cols size.CellCountInt) {
 	t.dynamic = new
 }
 
+// All returns the columns (0-indexed) with a tabstop, in order.
+func (t *Tabstops) All() []size.CellCountInt {
+	var cols []size.CellCountInt
+	for col := range t.cols {
+		if t.Get(col) {
+			cols = append(cols, col)
+		}
+	}
+	return cols
+}
+
 // Capacity returns the maximum number of columns this can support currently.
 func (t *Tabstops) Capacity() int {
 	return (preallocCount + len(t.dynamic)) * int(unitBits)
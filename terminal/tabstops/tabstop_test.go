@@ -67,3 +67,13 @@ func TestTabstopsCountOn80(t *testing.T) {
 		t.Errorf("tabstops count = %d, want 9", count)
 	}
 }
+
+func TestTabstopsAll(t *testing.T) {
+	tab := NewTabstops(30, TABSTOP_INTERVAL)
+	assert.Equal(t, []size.CellCountInt{8, 16, 24}, tab.All())
+	tab.Unset(16)
+	tab.Set(3)
+	assert.Equal(t, []size.CellCountInt{3, 8, 24}, tab.All())
+	tab.Reset(0)
+	assert.Empty(t, tab.All())
+}
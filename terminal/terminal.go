@@ -2,14 +2,19 @@ package terminal
 
 import (
 	"bytes"
+	"math"
 
 	"github.com/hnimtadd/termio/logger"
+	"github.com/hnimtadd/termio/terminal/charsets"
+	"github.com/hnimtadd/termio/terminal/color"
 	"github.com/hnimtadd/termio/terminal/coordinate"
 	"github.com/hnimtadd/termio/terminal/core"
 	pagepkg "github.com/hnimtadd/termio/terminal/page"
+	"github.com/hnimtadd/termio/terminal/pagelist"
 	"github.com/hnimtadd/termio/terminal/point"
 	"github.com/hnimtadd/termio/terminal/screen"
 	"github.com/hnimtadd/termio/terminal/sequences/csi"
+	"github.com/hnimtadd/termio/terminal/sequences/osc"
 	"github.com/hnimtadd/termio/terminal/set"
 	"github.com/hnimtadd/termio/terminal/sgr"
 	"github.com/hnimtadd/termio/terminal/size"
@@ -19,6 +24,9 @@ import (
 	dw "github.com/mattn/go-runewidth"
 )
 
+// The maximum depth of the title stack, this is the same limit as xterm.
+const maxTitleStackSize = 10
+
 type (
 	Options struct {
 		Cols int // The number of columns in the terminal
@@ -28,13 +36,24 @@ type (
 		// back to this state
 		Modes map[core.Mode]bool
 
+		// The color palette the terminal starts with and resets to. If nil,
+		// color.DefaultPalette is used.
+		Palette *color.Palette
+
+		// The identity reported to device attributes requests. If nil,
+		// DefaultDeviceIdentity is used.
+		DeviceIdentity *DeviceIdentity
+
 		Logger logger.Logger
 	}
 	// Terminal mainly implemented for terminal that used to
 	// execute 1 command only
 	Terminal struct {
-		// Screen-related fields
-		Screen *screen.Screen
+		// Screen-related fields. Screen is the active screen, the inactive
+		// one is kept aside until we switch back to it.
+		Screen          *screen.Screen
+		secondaryScreen *screen.Screen
+		activeScreen    ScreenType
 
 		// The size of the terminal
 		rows, cols size.CellCountInt
@@ -45,10 +64,28 @@ type (
 
 		pwd string // Current working directory
 
+		// The window title and icon name as set by OSC 0, OSC 1 and OSC 2.
+		title    string
+		iconName string
+
+		// The xterm title stacks, pushed by CSI 22 t and popped by CSI 23 t.
+		titleStack    []string
+		iconNameStack []string
+
 		// The previous printed character, we need this one for the repeat
 		// previous char CSI (ESC [ <n> b).
 		previousChar *uint32
 
+		// The color palette. Entries can be changed by the running program
+		// with OSC 4, the original is kept to reset them with OSC 104.
+		colorPalette struct {
+			original color.Palette
+			colors   color.Palette
+		}
+
+		// The identity reported to device attributes requests.
+		deviceIdentity DeviceIdentity
+
 		// Where the tabstops are.
 		tabstops *tabstops.Tabstops
 
@@ -58,6 +95,21 @@ type (
 		logger logger.Logger
 	}
 
+	// The identity the terminal reports to the device attributes (DA)
+	// requests of the host.
+	DeviceIdentity struct {
+		// The conformance level followed by the supported extensions,
+		// reported by the primary DA (CSI ? Ps ; ... c).
+		Primary []uint16
+
+		// The terminal type, the firmware version and the ROM cartridge
+		// number reported by the secondary DA (CSI > Pp ; Pv ; Pc c).
+		Type, Version, Cartridge uint16
+
+		// The unit ID reported by the tertiary DA (DCS ! | XXXXXXXX ST).
+		UnitID uint32
+	}
+
 	// Scroll region is the are of the screen designated where scolling
 	// occurs. When scrolling the screen, on this viewport is scroled.
 	ScrollingRegion struct {
@@ -74,15 +126,63 @@ type (
 	}
 )
 
+// The screens of a terminal. Full-screen programs such as vim or less switch
+// to the alternate screen so the content and the scrollback of the primary
+// screen are left untouched.
+type ScreenType uint8
+
+const (
+	ScreenTypePrimary ScreenType = iota
+	ScreenTypeAlternate
+)
+
+func (s ScreenType) String() string {
+	switch s {
+	case ScreenTypePrimary:
+		return "primary"
+	case ScreenTypeAlternate:
+		return "alternate"
+	default:
+		return "unknown"
+	}
+}
+
+// The default identity: a VT220 (62) with ANSI color (22), the same as
+// reported by ghostty.
+var DefaultDeviceIdentity = DeviceIdentity{
+	Primary: []uint16{62, 22},
+	Type:    1,
+	Version: 10,
+}
+
 func NewTerminal(opts Options) *Terminal {
-	return &Terminal{
+	palette := color.Palette(color.DefaultPalette)
+	if opts.Palette != nil {
+		palette = *opts.Palette
+	}
+	identity := DefaultDeviceIdentity
+	if opts.DeviceIdentity != nil {
+		identity = *opts.DeviceIdentity
+	}
+
+	// The alternate screen is used by full-screen programs, it never has
+	// scrollback.
+	alternate := screen.NewScreen(
+		size.CellCountInt(opts.Cols),
+		size.CellCountInt(opts.Rows),
+	)
+	alternate.NoScrollback = true
+
+	t := &Terminal{
 		Screen: screen.NewScreen(
 			size.CellCountInt(opts.Cols),
 			size.CellCountInt(opts.Rows),
 		),
-		rows:  size.CellCountInt(opts.Rows),
-		cols:  size.CellCountInt(opts.Cols),
-		Modes: core.NewModeState(opts.Modes, opts.Modes),
+		secondaryScreen: alternate,
+		activeScreen:    ScreenTypePrimary,
+		rows:            size.CellCountInt(opts.Rows),
+		cols:            size.CellCountInt(opts.Cols),
+		Modes:           core.NewModeState(opts.Modes, opts.Modes),
 		tabstops: tabstops.NewTabstops(
 			size.CellCountInt(opts.Cols),
 			tabstops.TABSTOP_INTERVAL,
@@ -93,9 +193,13 @@ func NewTerminal(opts Options) *Terminal {
 			left:   0,
 			right:  size.CellCountInt(opts.Cols) - 1,
 		},
-		pwd:    "",
-		logger: opts.Logger,
+		deviceIdentity: identity,
+		pwd:            "",
+		logger:         opts.Logger,
 	}
+	t.colorPalette.original = palette
+	t.colorPalette.colors = palette
+	return t
 }
 
 // Backspace moves the cursor back a column (but not less than 0).
@@ -228,14 +332,79 @@ func (t *Terminal) EraseInLine(mode csi.ELMode) {
 	t.Screen.ClearCells(cursor.PagePin.Node.Data, cursor.PageRow, start, end)
 }
 
+// Erase repeated characters from the cursor position rightward without
+// shifting the rest of the line (ECH). Erased cells get the background color
+// of the current SGR state. At least one character is erased and the erase
+// stops at the end of the line.
+//
+// This unsets the pending wrap state without wrapping.
+func (t *Terminal) EraseChars(repeated uint16) {
+	cursor := t.Screen.Cursor
+	start := cursor.X
+	end := min(t.cols, start+max(size.CellCountInt(repeated), 1))
+
+	// We don't want to split a multi-cell character, so the head of a wide
+	// character is erased with its tail and the other way around.
+	if start > 0 && cursor.PageCell.Wide == pagepkg.WideSpacerTail {
+		start--
+	}
+	if end < t.cols && cursor.PageRow.Cells[end-1].Wide == pagepkg.WideWide {
+		end++
+	}
+
+	cursor.PendingWrap = false
+	t.Screen.CursorMarkDirty()
+	t.Screen.ClearCells(cursor.PagePin.Node.Data, cursor.PageRow, start, end)
+}
+
+// Return the previous printed character, false if nothing was printed since
+// the last reset.
+func (t *Terminal) PreviousChar() (uint32, bool) {
+	if t.previousChar == nil {
+		return 0, false
+	}
+	return *t.previousChar, true
+}
+
 // Full reset.
 //
 // This will attempt to free the existing screen memory
 func (t *Terminal) FullReset() {
+	t.SwitchScreen(ScreenTypePrimary)
 	t.Screen.Reset()
+	t.secondaryScreen.Reset()
 	t.Modes.Reset()
+	t.ResetScrollingRegion()
 	t.previousChar = nil
 	t.pwd = ""
+	t.ResetPalette()
+}
+
+// Reset the state programs change to their defaults without touching the
+// screen contents, the scrollback or the cursor position (DECSTR): the
+// insert, origin, wraparound, margin, cursor keys and keypad modes, the
+// cursor visibility, the style, the scrolling region, the saved cursor and
+// the character sets.
+func (t *Terminal) SoftReset() {
+	for _, mode := range []core.Mode{
+		core.ModeInsert,
+		core.ModeOrigin,
+		core.ModeWraparound,
+		core.ModeEnableLeftAndRightMargin,
+		core.ModeDisableKeyboard,
+		core.ModeCursorVisible,
+		core.ModeCursorKeys,
+		core.ModeKeypadKeys,
+	} {
+		t.Modes.Set(mode, mode.Default)
+	}
+	t.Screen.Cursor.Visible = true
+	t.ResetScrollingRegion()
+	t.Screen.Cursor.PendingWrap = false
+	t.Screen.SetAttribute(sgr.Attribute{Type: sgr.AttributeTypeUnset})
+	t.Screen.SavedCursor = nil
+	t.Screen.Charset = charsets.NewState()
+	t.previousChar = nil
 }
 
 // Linefeed moves the cursor to the next line.
@@ -253,6 +422,9 @@ func (t *Terminal) Print(c uint32) {
 	defer t.Screen.AssertIntegrity()
 	var rightLimit size.CellCountInt
 
+	// Replace the character with the one of the invoked character set.
+	c = t.Screen.Charset.Map(c)
+
 	// our right margin depends where our cursor is now
 	if t.Screen.Cursor.X > t.scrollingRegion.right {
 		rightLimit = t.cols
@@ -310,14 +482,16 @@ func (t *Terminal) Print(c uint32) {
 				// If we don't have wraparound enabled, then we don't print
 				// this character at all and don't move the cursor.
 				// This is how xterm behaves
-				if t.Modes.Get(core.ModeWraparound) {
+				if !t.Modes.Get(core.ModeWraparound) {
 					return
 				}
 
+				// We only create a spacer head at the real edge of the
+				// screen, otherwise the cell is cleared with a narrow.
 				if rightLimit == t.cols {
-					t.printCell(c, pagepkg.WideSpacerHead)
+					t.printCell(0, pagepkg.WideSpacerHead)
 				} else {
-					t.printCell(c, pagepkg.WideNarrow)
+					t.printCell(0, pagepkg.WideNarrow)
 				}
 				t.PrintWrap()
 			}
@@ -337,8 +511,9 @@ func (t *Terminal) Print(c uint32) {
 	}
 
 	// If we are at the end of the line, we need to wrap the next time
-	// In this case, we don't move the cursor
-	if t.Screen.Cursor.X+width == rightLimit {
+	// In this case, we don't move the cursor. A wide char already moved the
+	// cursor to its spacer tail.
+	if t.Screen.Cursor.X+1 == rightLimit {
 		t.Screen.Cursor.PendingWrap = true
 		return
 	}
@@ -448,6 +623,7 @@ func (t *Terminal) printCell(c uint32, wide pagepkg.Wide) {
 		(*cell).StyleID = cursor.StyleID
 		(*cell).Wide = wide
 	}
+	t.Screen.CursorSetHyperlink()
 
 	if styleChanged {
 		page := cursor.PagePin.Node.Data
@@ -460,9 +636,38 @@ func (t *Terminal) printCell(c uint32, wide pagepkg.Wide) {
 	}
 }
 
-// SetCursorRow implements terminalHandler.
+// SetCursorRow moves the cursor to the row (1-indexed) without changing the
+// column (VPA). Like SetCursorPosition, the row is relative to the top margin
+// and limited to the scrolling region in origin mode.
 func (t *Terminal) SetCursorRow(row uint16) {
-	t.Screen.SetCursorHorizontalAbs(size.CellCountInt(row))
+	_, col := t.CursorReportPosition()
+	t.SetCursorPosition(row, uint16(col))
+}
+
+// SetCursorCol moves the cursor to the column (1-indexed) without changing
+// the row (HPA). Like SetCursorPosition, the column is relative to the left
+// margin and limited to the scrolling region in origin mode.
+func (t *Terminal) SetCursorCol(col uint16) {
+	row, _ := t.CursorReportPosition()
+	t.SetCursorPosition(uint16(row), col)
+}
+
+// SetCursorRowRelative moves the cursor down offset rows, at least one,
+// without changing the column (VPR). Unlike SetCursorDown the bottom margin
+// only stops the cursor in origin mode.
+func (t *Terminal) SetCursorRowRelative(offset uint16) {
+	row, col := t.CursorReportPosition()
+	row = min(row+int(max(offset, 1)), math.MaxUint16)
+	t.SetCursorPosition(uint16(row), uint16(col))
+}
+
+// SetCursorColRelative moves the cursor right offset columns, at least one,
+// without changing the row (HPR). Unlike SetCursorRight the right margin only
+// stops the cursor in origin mode.
+func (t *Terminal) SetCursorColRelative(offset uint16) {
+	row, col := t.CursorReportPosition()
+	col = min(col+int(max(offset, 1)), math.MaxUint16)
+	t.SetCursorPosition(uint16(row), uint16(col))
 }
 
 func (t *Terminal) SetCursorTabStop() {
@@ -551,27 +756,45 @@ func (t *Terminal) SetCursorRight(offset uint16) {
 	t.Screen.SetCursorRight(size.CellCountInt(offset))
 }
 
-// SetCursorTabRight move the cursor to the next tabstop, clearing the screen
-// toe the left of the tabstop.
+// SetCursorTabRight move the cursor to the repeated next tabstop, or to the
+// right margin if there are no further tabstops (HT, CHT). A cursor right of
+// the right margin moves up to the last column instead.
 func (t *Terminal) SetCursorTabRight(repeated uint16) {
-	for range repeated {
-		for t.Screen.Cursor.X < t.cols {
-			// Move the cursor right
-			t.Screen.SetCursorRight(1)
+	for range max(repeated, 1) {
+		t.cursorTabRight()
+	}
+}
 
-			// If the last cursor position was a tabstop, we return. We do
-			// "last cursor position" becasue we want a space to be written
-			// at the tab stop unless we're at the end.
-			if t.tabstops.Get(t.Screen.Cursor.X) {
-				return
-			}
+func (t *Terminal) cursorTabRight() {
+	// our right limit depends where our cursor is now
+	var rightLimit size.CellCountInt
+	if t.Screen.Cursor.X > t.scrollingRegion.right {
+		rightLimit = t.cols - 1
+	} else {
+		rightLimit = t.scrollingRegion.right
+	}
+	for t.Screen.Cursor.X < rightLimit {
+		// Move the cursor right
+		t.Screen.SetCursorRight(1)
+
+		// If the last cursor position was a tabstop, we return. We do
+		// "last cursor position" becasue we want a space to be written
+		// at the tab stop unless we're at the end.
+		if t.tabstops.Get(t.Screen.Cursor.X) {
+			return
 		}
 	}
 }
 
 // SetCursorTabLeft similar to SetCursorTabRight, but move the cursor to the
-// previous tabstop instead
+// repeated previous tabstop instead (CBT)
 func (t *Terminal) SetCursorTabLeft(repeated uint16) {
+	for range max(repeated, 1) {
+		t.cursorTabLeft()
+	}
+}
+
+func (t *Terminal) cursorTabLeft() {
 	var leftLimit size.CellCountInt
 	// With origin mode enabled, our leftmost limit is the left margin
 	if t.Modes.Get(core.ModeOrigin) {
@@ -579,14 +802,12 @@ func (t *Terminal) SetCursorTabLeft(repeated uint16) {
 	} else {
 		leftLimit = 0
 	}
-	for range repeated {
-		for t.Screen.Cursor.X > leftLimit {
-			// Move the cursor left
-			t.Screen.SetCursorLeft(1)
+	for t.Screen.Cursor.X > leftLimit {
+		// Move the cursor left
+		t.Screen.SetCursorLeft(1)
 
-			if t.tabstops.Get(t.Screen.Cursor.X) {
-				return
-			}
+		if t.tabstops.Get(t.Screen.Cursor.X) {
+			return
 		}
 	}
 }
@@ -601,6 +822,28 @@ func (t *Terminal) TabSet() {
 	t.tabstops.Set(t.Screen.Cursor.X)
 }
 
+// Clear the tabstop at the cursor or all tabstops (TBC).
+func (t *Terminal) TabClear(mode csi.TBCMode) {
+	switch mode {
+	case csi.TBCModeCurrent:
+		t.tabstops.Unset(t.Screen.Cursor.X)
+	case csi.TBCModeAll:
+		t.tabstops.Reset(0)
+	default:
+		t.logger.Warn("invalid tab clear mode", "mode", mode)
+	}
+}
+
+// Reset the tabstops to every 8 columns (DECST8C).
+func (t *Terminal) TabReset() {
+	t.tabstops.Reset(tabstops.TABSTOP_INTERVAL)
+}
+
+// Return the columns (0-indexed) with a tabstop, in order.
+func (t *Terminal) Tabstops() []size.CellCountInt {
+	return t.tabstops.All()
+}
+
 // Moves the cursor to the next line.
 //
 // If the cursor is outside of the scrolling region: move the cursor one line
@@ -635,14 +878,25 @@ func (t *Terminal) Index() {
 		t.Screen.Cursor.X >= t.scrollingRegion.left &&
 		t.Screen.Cursor.X <= t.scrollingRegion.right {
 
-		// If our scrlling region is at the top, we create scrollback.
-		if t.scrollingRegion.top == 0 &&
+		// If our scrlling region is at the top, we create scrollback. The
+		// alternate screen has no scrollback so its rows are always erased.
+		if !t.Screen.NoScrollback &&
+			t.scrollingRegion.top == 0 &&
 			t.scrollingRegion.left == 0 &&
 			t.scrollingRegion.right == t.cols-1 {
 			t.Screen.SetCursorScrollUp()
 			return
 
 		}
+
+		// With left and right margins only the cells within the margins
+		// scroll, the rows can't be erased as a whole.
+		if t.scrollingRegion.left > 0 ||
+			t.scrollingRegion.right < t.cols-1 {
+			t.cursorScrollUp(1)
+			return
+		}
+
 		// Preserve old cursor just for assertions.
 		oldCursor := t.Screen.Cursor
 
@@ -785,6 +1039,46 @@ func (t *Terminal) cursorScrollUp(repeated uint16) {
 	t.DeleteLines(repeated)
 }
 
+// Scroll the text of the scrolling region up by repeated lines (SU), at least
+// one. Lines are removed from the top of the region and blank lines are added
+// at the bottom. If the region starts at the top of the screen and has no
+// left and right margins, the removed lines go to the scrollback.
+//
+// Does not change the cursor position nor the pending wrap state.
+func (t *Terminal) ScrollUp(repeated uint16) {
+	repeated = max(repeated, 1)
+	if t.Screen.NoScrollback ||
+		t.scrollingRegion.top != 0 ||
+		t.scrollingRegion.left != 0 ||
+		t.scrollingRegion.right != t.cols-1 {
+		t.cursorScrollUp(repeated)
+		return
+	}
+
+	oldX, oldY, oldWrap := t.Screen.Cursor.X, t.Screen.Cursor.Y, t.Screen.Cursor.PendingWrap
+	defer func() {
+		t.Screen.SetCursorAbs(oldX, oldY)
+		t.Screen.Cursor.PendingWrap = oldWrap
+	}()
+
+	// Scrolling more than the height of the region only adds blank lines to
+	// the scrollback.
+	height := t.scrollingRegion.bottom - t.scrollingRegion.top + 1
+	t.Screen.SetCursorAbs(t.scrollingRegion.left, t.scrollingRegion.bottom)
+	for range min(size.CellCountInt(repeated), height) {
+		t.Index()
+	}
+}
+
+// Scroll the text of the scrolling region down by repeated lines (SD), at
+// least one. Blank lines are added at the top of the region and the lines
+// pushed out of the bottom are lost.
+//
+// Does not change the cursor position nor the pending wrap state.
+func (t *Terminal) ScrollDown(repeated uint16) {
+	t.cursorScrollDown(max(repeated, 1))
+}
+
 // Scroll the text down by one row.
 func (t *Terminal) cursorScrollDown(repeated uint16) {
 	// Preserve our x/y to restore
@@ -794,8 +1088,8 @@ func (t *Terminal) cursorScrollDown(repeated uint16) {
 		t.Screen.Cursor.PendingWrap = oldWrap
 	}()
 
-	// Move the cursor to the top of the screen
-	t.Screen.SetCursorAbs(0, 0)
+	// Move the cursor to the top of the scroll region
+	t.Screen.SetCursorAbs(t.scrollingRegion.left, t.scrollingRegion.top)
 	t.InsertLines(repeated)
 }
 
@@ -829,10 +1123,11 @@ func (t *Terminal) InsertLines(repeated uint16) {
 		return
 	}
 
-	// At the end, we need to return the cursor to the row it started on.
+	// At the end, we need to return the cursor to the left margin of the
+	// row it started on.
 	startY := t.Screen.Cursor.Y
 	defer func() {
-		t.Screen.SetCursorAbs(startY, startY)
+		t.Screen.SetCursorAbs(t.scrollingRegion.left, startY)
 		// Always reset pending wrap state
 		t.Screen.Cursor.PendingWrap = false
 	}()
@@ -855,12 +1150,11 @@ func (t *Terminal) InsertLines(repeated uint16) {
 	leftRight := t.scrollingRegion.left > 0 ||
 		t.scrollingRegion.right < t.cols-1
 
-	// Remaining rows from our cursor to the bottom of the screen
-	rem := t.rows - t.Screen.Cursor.Y + 1
-	// t.screen.InsertNewLines(repeated)
+	// Remaining rows from our cursor to the bottom of the scrolling region
+	rem := t.scrollingRegion.bottom - t.Screen.Cursor.Y + 1
 
-	// We can only insert delete up to our remaining lines in the screen, so we
-	// take wichever is smaller
+	// We can only insert up to our remaining lines in the scrolling region,
+	// so we take wichever is smaller
 	adjustedCount := min(size.CellCountInt(repeated), rem)
 
 	// Create a new tracked pin which we will use to navigate the page list
@@ -916,6 +1210,8 @@ func (t *Terminal) InsertLines(repeated uint16) {
 					dstRow.SemanticPrompt = srcRow.SemanticPrompt
 					dstRow.WrapContinuation = srcRow.WrapContinuation
 					dstRow.Wrap = srcRow.Wrap
+					dstRow.Styled = srcRow.Styled
+					dstRow.Hyperlink = srcRow.Hyperlink
 
 					*srcRow = dst
 
@@ -938,6 +1234,11 @@ func (t *Terminal) InsertLines(repeated uint16) {
 			t.Screen.ClearCells(page, curRow,
 				t.scrollingRegion.left, t.scrollingRegion.right+1)
 		}
+
+		// Move our pin up to the next row.
+		if up := curP.Up(1); up != nil {
+			*curP = *up
+		}
 	}
 }
 
@@ -971,10 +1272,11 @@ func (t *Terminal) DeleteLines(repeated uint16) {
 		return
 	}
 
-	// At the end, we need to return the cursor to the row it started on.
+	// At the end, we need to return the cursor to the left margin of the
+	// row it started on.
 	startY := t.Screen.Cursor.Y
 	defer func() {
-		t.Screen.SetCursorAbs(startY, startY)
+		t.Screen.SetCursorAbs(t.scrollingRegion.left, startY)
 		// Always reset pending wrap state
 		t.Screen.Cursor.PendingWrap = false
 	}()
@@ -995,13 +1297,13 @@ func (t *Terminal) DeleteLines(repeated uint16) {
 
 	// We have a slower path if we have left or right scroll margin.
 	leftRight := t.scrollingRegion.left > 0 ||
-		t.scrollingRegion.right < t.cols
+		t.scrollingRegion.right < t.cols-1
 
-	// Remaining rows from our cursor to the bottom of the screen
-	rem := t.rows - t.Screen.Cursor.Y + 1
+	// Remaining rows from our cursor to the bottom of the scrolling region
+	rem := t.scrollingRegion.bottom - t.Screen.Cursor.Y + 1
 
-	// We can only insert delete up to our remaining lines in the screen, so we
-	// take wichever is smaller
+	// We can only delete up to our remaining lines in the scrolling region,
+	// so we take wichever is smaller
 	adjustedCount := min(size.CellCountInt(repeated), rem)
 
 	// Create a new tracked pin which we will use to navigate the curP list
@@ -1014,7 +1316,7 @@ func (t *Terminal) DeleteLines(repeated uint16) {
 		curP.MarkDirty()
 
 		// If this is one of the lines we need to shift, do so
-		if y < adjustedCount {
+		if y < rem-adjustedCount {
 			offPage := curP.Down(adjustedCount)
 			offRAC := offPage.RowAndCell()
 			offRow := offRAC.Row
@@ -1053,6 +1355,8 @@ func (t *Terminal) DeleteLines(repeated uint16) {
 					dstRow.SemanticPrompt = srcRow.SemanticPrompt
 					dstRow.WrapContinuation = srcRow.WrapContinuation
 					dstRow.Wrap = srcRow.Wrap
+					dstRow.Styled = srcRow.Styled
+					dstRow.Hyperlink = srcRow.Hyperlink
 
 					*srcRow = dst
 
@@ -1113,7 +1417,7 @@ func (t *Terminal) InsertBlanks(repeated uint16) {
 	}
 
 	// Remaining cols from our cursor to the right margin
-	rem := t.cols - cursor.X + 1
+	rem := t.scrollingRegion.right - cursor.X + 1
 
 	// We can only insert blanks up to our remaining cols
 	adjustedCount := min(size.CellCountInt(repeated), rem)
@@ -1132,14 +1436,19 @@ func (t *Terminal) InsertBlanks(repeated uint16) {
 		end := cursor.PageRow.Cells[x]
 		if end.Wide == pagepkg.WideWide {
 			utils.Assert(cursor.PageRow.Cells[x+1].Wide == pagepkg.WideSpacerTail)
-			t.Screen.ClearCells(page, cursor.PageRow, x, x+1)
+			t.Screen.ClearCells(page, cursor.PageRow, x, x+2)
 		}
 
-		// We work backwards, so we don't overwrite data.
-		for ; x >= leftX; x-- {
+		// We work backwards, so we don't overwrite data. x is unsigned so
+		// we stop after swapping the cell at leftX.
+		for {
 			src := cursor.PageRow.Cells[x]
 			dst := cursor.PageRow.Cells[x+adjustedCount]
 			page.SwapCells(src, dst)
+			if x == leftX {
+				break
+			}
+			x--
 		}
 	}
 
@@ -1177,9 +1486,9 @@ func (t *Terminal) DeleteChars(repeated uint16) {
 	page := cursor.PagePin.Node.Data
 
 	// Remaining cols from our cursor to the right margin
-	rem := t.cols - t.Screen.Cursor.X + 1
+	rem := t.scrollingRegion.right - t.Screen.Cursor.X + 1
 
-	// We can only insert blanks up to our remaining cols
+	// We can only delete up to our remaining cols
 	count := min(size.CellCountInt(repeated), rem)
 
 	t.Screen.SplitCellBoundary(t.Screen.Cursor.X)
@@ -1187,7 +1496,7 @@ func (t *Terminal) DeleteChars(repeated uint16) {
 	t.Screen.SplitCellBoundary(t.scrollingRegion.right + 1)
 
 	// This is the amount of space at the right of the line that will not
-	// be blank, so we need to shift the correct cols right.
+	// be blank, so we need to shift the correct cols left.
 	// "amount" is the number of such cols.
 	amount := rem - count
 	x := leftX
@@ -1197,7 +1506,13 @@ func (t *Terminal) DeleteChars(repeated uint16) {
 
 		rightX := leftX + (amount - 1)
 
-		for ; x < rightX; x++ {
+		// If the last cell we're shifting is the tail of a wide char, the
+		// head is deleted so the tail must go too.
+		if end := cursor.PageRow.Cells[rightX+count]; end.Wide == pagepkg.WideSpacerTail {
+			t.Screen.ClearCells(page, cursor.PageRow, rightX+count-1, rightX+count+1)
+		}
+
+		for ; x <= rightX; x++ {
 			src := cursor.PageRow.Cells[x+count]
 			dst := cursor.PageRow.Cells[x]
 			page.SwapCells(src, dst)
@@ -1205,9 +1520,10 @@ func (t *Terminal) DeleteChars(repeated uint16) {
 	}
 
 	// Insert blanks. The blanks preserve the background color.
-	t.Screen.ClearCells(page, cursor.PageRow, leftX, leftX+amount)
+	t.Screen.ClearCells(page, cursor.PageRow, x, x+count)
 
 	// Our row's soft-wrap is always reset
+	cursor.PageRow.Wrap = false
 
 	// Our row is always dirty
 	t.Screen.CursorMarkDirty()
@@ -1235,6 +1551,45 @@ func (t *Terminal) MarkSemanticPrompt(prompt pagepkg.SemanticPromptType) {
 	}
 }
 
+// Track the current cursor position. The returned pin is kept up to date as
+// the screen scrolls, callers must release it with UntrackPin.
+func (t *Terminal) TrackCursorPin() *pagelist.Pin {
+	return t.Screen.Pages.TrackPin(*t.Screen.Cursor.PagePin)
+}
+
+// Release a pin returned by TrackCursorPin.
+func (t *Terminal) UntrackPin(pin *pagelist.Pin) {
+	// The screen may have been switched since the pin was tracked.
+	t.Screen.Pages.UntrackPin(pin)
+	t.secondaryScreen.Pages.UntrackPin(pin)
+}
+
+// Return the text starting at the given pin up to, but not including, the
+// cursor. This is used to read what was written since a position was
+// marked, e.g. the prompt or the command line with semantic prompts.
+func (t *Terminal) StringFromPin(start *pagelist.Pin) string {
+	end := *t.Screen.Cursor.PagePin
+	if end.X > 0 {
+		end.X--
+	} else {
+		up := end.Up(1)
+		if up == nil {
+			return ""
+		}
+		end = *up
+		end.X = t.cols - 1
+	}
+	if end.Before(start) {
+		return ""
+	}
+
+	w := bytes.NewBuffer(nil)
+	if err := t.Screen.DumpStringBetween(w, *start, end); err != nil {
+		return ""
+	}
+	return w.String()
+}
+
 // Returns true if the cursor is currently at a prompt. Another way to look
 // at this is it returns false if the shell is currently outputing something.
 // This requires shll integration (sematic prompt integration).
@@ -1282,33 +1637,328 @@ func (t *Terminal) Resize(cols, rows size.CellCountInt) {
 		return
 	}
 
-	// Resize the tabstops
+	// Resize the tabstops. The tabstops set by the program are kept, the
+	// new columns get the default ones.
 	if t.cols != cols {
-		t.tabstops = tabstops.NewTabstops(cols, tabstops.TABSTOP_INTERVAL)
+		t.tabstops.Resize(cols)
+		for col := t.cols; col < cols; col++ {
+			if col%tabstops.TABSTOP_INTERVAL == 0 && col < cols-1 {
+				t.tabstops.Set(col)
+			} else {
+				t.tabstops.Unset(col)
+			}
+		}
+	}
+	// Only the primary screen reflows, the alternate screen is redrawn by
+	// the program on resize anyway.
+	primary, alternate := t.Screen, t.secondaryScreen
+	if t.activeScreen == ScreenTypeAlternate {
+		primary, alternate = alternate, primary
 	}
 	if t.Modes.Get(core.ModeWraparound) {
-		t.Screen.ResizeWithReflow(cols, rows)
+		primary.ResizeWithReflow(cols, rows)
 	} else {
 		// If we're making the screen smaller, re-flow the screen
-		t.Screen.ResizeWithoutReflow(cols, rows)
+		primary.ResizeWithoutReflow(cols, rows)
 	}
+	alternate.ResizeWithoutReflow(cols, rows)
 
 	t.cols = cols
 	t.rows = rows
 
+	t.ResetScrollingRegion()
+}
+
+// Reset the scrolling region to the full screen.
+func (t *Terminal) ResetScrollingRegion() {
 	t.scrollingRegion = &ScrollingRegion{
 		top:    0,
-		bottom: rows - 1,
+		bottom: t.rows - 1,
 		left:   0,
-		right:  cols - 1,
+		right:  t.cols - 1,
+	}
+}
+
+// Set the top and bottom margins of the scrolling region (DECSTBM), the
+// margins are 1-indexed and 0 means the edge of the screen. The margins are
+// ignored if the region would have less than 2 rows. The cursor moves to the
+// home position.
+func (t *Terminal) SetTopAndBottomMargin(top, bottom uint16) {
+	itop := max(1, size.CellCountInt(top))
+	ibottom := t.rows
+	if bottom != 0 {
+		ibottom = min(t.rows, size.CellCountInt(bottom))
+	}
+	if itop >= ibottom {
+		return
+	}
+
+	t.scrollingRegion.top = itop - 1
+	t.scrollingRegion.bottom = ibottom - 1
+	t.SetCursorPosition(1, 1)
+}
+
+// Set the left and right margins of the scrolling region (DECSLRM), the
+// margins are 1-indexed and 0 means the edge of the screen. This does
+// nothing unless the DECLRMM mode is set. The margins are ignored if the
+// region would have less than 2 columns. The cursor moves to the home
+// position.
+func (t *Terminal) SetLeftAndRightMargin(left, right uint16) {
+	if !t.Modes.Get(core.ModeEnableLeftAndRightMargin) {
+		return
+	}
+
+	ileft := max(1, size.CellCountInt(left))
+	iright := t.cols
+	if right != 0 {
+		iright = min(t.cols, size.CellCountInt(right))
+	}
+	if ileft >= iright {
+		return
+	}
+
+	t.scrollingRegion.left = ileft - 1
+	t.scrollingRegion.right = iright - 1
+	t.SetCursorPosition(1, 1)
+}
+
+// Set a mode, including the side effects of changing the mode on the rest
+// of the terminal state.
+func (t *Terminal) SetMode(mode core.Mode, enabled bool) {
+	t.Modes.Set(mode, enabled)
+
+	switch mode {
+	// Changing the origin mode moves the cursor to the new home position.
+	case core.ModeOrigin:
+		t.SetCursorPosition(1, 1)
+
+	// Without DECLRMM there are no left and right margins.
+	case core.ModeEnableLeftAndRightMargin:
+		if !enabled {
+			t.scrollingRegion.left = 0
+			t.scrollingRegion.right = t.cols - 1
+		}
+
+	case core.ModeCursorVisible:
+		t.Screen.Cursor.Visible = enabled
+
+	// Only one mouse tracking mode and one format is used at a time, like
+	// xterm resetting any of them goes back to no tracking or the X10
+	// format.
+	case core.ModeMouseX10,
+		core.ModeMouseNormal,
+		core.ModeMouseButton,
+		core.ModeMouseAny:
+		for _, other := range core.MouseTrackingModes {
+			if other != mode {
+				t.Modes.Set(other, false)
+			}
+		}
+	case core.ModeMouseFormatUTF8,
+		core.ModeMouseFormatSGR,
+		core.ModeMouseFormatURxvt,
+		core.ModeMouseFormatSGRPixels:
+		for _, other := range core.MouseFormatModes {
+			if other != mode {
+				t.Modes.Set(other, false)
+			}
+		}
+
+	case core.ModeAltScreenLegacy,
+		core.ModeAltScreen,
+		core.ModeAltScreenSaveCursor:
+		t.switchScreenMode(mode, enabled)
 	}
 }
 
+// Set the shape of the cursor and whether it blinks (DECSCUSR).
+func (t *Terminal) SetCursorStyle(style csi.CursorStyle) {
+	cursor := t.Screen.Cursor
+	switch style {
+	case csi.CursorStyleDefault, csi.CursorStyleBlinkingBlock:
+		cursor.Shape, cursor.Blinking = screen.CursorShapeBlock, true
+	case csi.CursorStyleSteadyBlock:
+		cursor.Shape, cursor.Blinking = screen.CursorShapeBlock, false
+	case csi.CursorStyleBlinkingUnderline:
+		cursor.Shape, cursor.Blinking = screen.CursorShapeUnderline, true
+	case csi.CursorStyleSteadyUnderline:
+		cursor.Shape, cursor.Blinking = screen.CursorShapeUnderline, false
+	case csi.CursorStyleBlinkingBar:
+		cursor.Shape, cursor.Blinking = screen.CursorShapeBar, true
+	case csi.CursorStyleSteadyBar:
+		cursor.Shape, cursor.Blinking = screen.CursorShapeBar, false
+	}
+}
+
+// Return the screen that is currently shown.
+func (t *Terminal) ActiveScreen() ScreenType {
+	return t.activeScreen
+}
+
+// Switch to the given screen. Each screen keeps its own content, the cursor
+// and the character sets are shared so they are brought along. Returns false
+// if the screen was already active.
+func (t *Terminal) SwitchScreen(to ScreenType) bool {
+	if t.activeScreen == to {
+		return false
+	}
+
+	old, charset := t.Screen.Cursor, t.Screen.Charset
+	t.Screen, t.secondaryScreen = t.secondaryScreen, t.Screen
+	t.activeScreen = to
+	t.Screen.Charset = charset
+
+	t.Screen.SetCursorAbs(old.X, old.Y)
+	t.Screen.Cursor.PendingWrap = old.PendingWrap
+	t.Screen.Cursor.Shape = old.Shape
+	t.Screen.Cursor.Blinking = old.Blinking
+	t.Screen.Cursor.Visible = old.Visible
+	t.Screen.Cursor.Style = old.Style
+	t.Screen.ManualStyleUpdate()
+	return true
+}
+
+// Switch screens for the alternate screen modes:
+//   - 47: switch to the alternate screen and back.
+//   - 1047: like 47, the alternate screen is cleared when leaving it.
+//   - 1049: save the cursor and switch to the cleared alternate screen,
+//     the cursor is restored when switching back.
+//
+// See: https://invisible-island.net/xterm/ctlseqs/ctlseqs.html#h2-The-Alternate-Screen-Buffer
+func (t *Terminal) switchScreenMode(mode core.Mode, enabled bool) {
+	if enabled {
+		if t.activeScreen == ScreenTypeAlternate {
+			return
+		}
+		if mode == core.ModeAltScreenSaveCursor {
+			t.SaveCursor()
+		}
+		t.SwitchScreen(ScreenTypeAlternate)
+		if mode == core.ModeAltScreenSaveCursor {
+			t.EraseInDisplay(csi.EDModeComplete)
+		}
+		return
+	}
+
+	if t.activeScreen == ScreenTypePrimary {
+		return
+	}
+	if mode == core.ModeAltScreen {
+		t.EraseInDisplay(csi.EDModeComplete)
+	}
+	t.SwitchScreen(ScreenTypePrimary)
+	if mode == core.ModeAltScreenSaveCursor {
+		t.RestoreCursor()
+	}
+}
+
+// Save the cursor position, pending wrap state, style, origin mode and
+// character sets on the active screen (DECSC). Each screen has a single
+// saved cursor, saving again overwrites it.
+func (t *Terminal) SaveCursor() {
+	t.Screen.SavedCursor = &screen.SavedCursor{
+		X:           t.Screen.Cursor.X,
+		Y:           t.Screen.Cursor.Y,
+		PendingWrap: t.Screen.Cursor.PendingWrap,
+		Style:       t.Screen.Cursor.Style,
+		Origin:      t.Modes.Get(core.ModeOrigin),
+		Charset:     t.Screen.Charset,
+	}
+}
+
+// Restore the cursor saved on the active screen (DECRC). Without a saved
+// cursor, the cursor moves to the home position with the default style,
+// origin mode and character sets.
+func (t *Terminal) RestoreCursor() {
+	saved := t.Screen.SavedCursor
+	if saved == nil {
+		saved = &screen.SavedCursor{Charset: charsets.NewState()}
+	}
+
+	// The mode is set directly, setting origin mode with SetMode would
+	// move the cursor.
+	t.Modes.Set(core.ModeOrigin, saved.Origin)
+	t.Screen.Charset = saved.Charset
+	t.Screen.Cursor.Style = saved.Style
+	t.Screen.ManualStyleUpdate()
+	t.Screen.SetCursorAbs(min(saved.X, t.cols-1), min(saved.Y, t.rows-1))
+	t.Screen.Cursor.PendingWrap = saved.PendingWrap
+}
+
+// Designate the character set to the slot (SCS), e.g. ESC ( 0 selects the
+// DEC special graphics in G0.
+func (t *Terminal) ConfigureCharset(slot charsets.Slot, set charsets.Charset) {
+	t.Screen.Charset.Configure(slot, set)
+}
+
+// Invoke the slot into the active area (SI, SO, LS2, LS3, ...), or for the
+// next printed character only if single is set (SS2, SS3).
+func (t *Terminal) InvokeCharset(active charsets.ActiveSlot, slot charsets.Slot, single bool) {
+	t.Screen.Charset.Invoke(active, slot, single)
+}
+
 // Set a style attibute
 func (t *Terminal) SetAttribute(attr sgr.Attribute) {
 	t.Screen.SetAttribute(attr)
 }
 
+// Start a hyperlink (OSC 8), see Screen.StartHyperlink
+func (t *Terminal) StartHyperlink(uri, id string) {
+	t.Screen.StartHyperlink(uri, id)
+}
+
+// End the current hyperlink (OSC 8)
+func (t *Terminal) EndHyperlink() {
+	t.Screen.EndHyperlink()
+}
+
+// Return the hyperlink under the given point, see Screen.HyperlinkAt
+func (t *Terminal) HyperlinkAt(pt point.Point) *screen.HyperlinkRange {
+	return t.Screen.HyperlinkAt(pt)
+}
+
+// Return the identity reported to device attributes requests
+func (t *Terminal) DeviceIdentity() DeviceIdentity {
+	return t.deviceIdentity
+}
+
+// Return the 1-based cursor position as reported to the host by CPR. In
+// origin mode the position is relative to the scrolling region.
+func (t *Terminal) CursorReportPosition() (row, col int) {
+	x, y := t.Screen.Cursor.X, t.Screen.Cursor.Y
+	if t.Modes.Get(core.ModeOrigin) {
+		x -= min(x, t.scrollingRegion.left)
+		y -= min(y, t.scrollingRegion.top)
+	}
+	return int(y) + 1, int(x) + 1
+}
+
+// Return the current color palette
+func (t *Terminal) Palette() color.Palette {
+	return t.colorPalette.colors
+}
+
+// Return the current color of the palette entry
+func (t *Terminal) PaletteColor(index uint8) color.RGB {
+	return t.colorPalette.colors[index]
+}
+
+// Change the color of the palette entry (OSC 4)
+func (t *Terminal) SetPaletteColor(index uint8, rgb color.RGB) {
+	t.colorPalette.colors[index] = rgb
+}
+
+// Reset the palette entry to its original color (OSC 104)
+func (t *Terminal) ResetPaletteColor(index uint8) {
+	t.colorPalette.colors[index] = t.colorPalette.original[index]
+}
+
+// Reset the whole palette to its original colors (OSC 104 without
+// parameters)
+func (t *Terminal) ResetPalette() {
+	t.colorPalette.colors = t.colorPalette.original
+}
+
 // Set the pwd for the terminal
 func (t *Terminal) SetPwd(pwd string) {
 	t.pwd = pwd
@@ -1319,6 +1969,62 @@ func (t *Terminal) GetPwd() string {
 	return t.pwd
 }
 
+// Set the window title
+func (t *Terminal) SetTitle(title string) {
+	t.title = title
+}
+
+// function to get the current window title
+func (t *Terminal) GetTitle() string {
+	return t.title
+}
+
+// Set the icon name
+func (t *Terminal) SetIconName(name string) {
+	t.iconName = name
+}
+
+// function to get the current icon name
+func (t *Terminal) GetIconName() string {
+	return t.iconName
+}
+
+// PushTitle saves the window title and/or the icon name on the title stack
+// (CSI 22 t). As in xterm, the stack holds at most maxTitleStackSize
+// entries, the oldest entry is dropped when the stack is full.
+func (t *Terminal) PushTitle(target osc.TitleTarget) {
+	push := func(stack []string, value string) []string {
+		if len(stack) >= maxTitleStackSize {
+			stack = stack[1:]
+		}
+		return append(stack, value)
+	}
+	if target == osc.TitleTargetBoth || target == osc.TitleTargetWindow {
+		t.titleStack = push(t.titleStack, t.title)
+	}
+	if target == osc.TitleTargetBoth || target == osc.TitleTargetIcon {
+		t.iconNameStack = push(t.iconNameStack, t.iconName)
+	}
+}
+
+// PopTitle restores the window title and/or the icon name from the title
+// stack (CSI 23 t). Popping an empty stack does nothing.
+func (t *Terminal) PopTitle(target osc.TitleTarget) {
+	pop := func(stack []string, value *string) []string {
+		if len(stack) == 0 {
+			return stack
+		}
+		*value = stack[len(stack)-1]
+		return stack[:len(stack)-1]
+	}
+	if target == osc.TitleTargetBoth || target == osc.TitleTargetWindow {
+		t.titleStack = pop(t.titleStack, &t.title)
+	}
+	if target == osc.TitleTargetBoth || target == osc.TitleTargetIcon {
+		t.iconNameStack = pop(t.iconNameStack, &t.iconName)
+	}
+}
+
 // Returns true if the point is dirty, used for testing.
 func (t *Terminal) isDirty(pt point.Point) bool {
 	return t.Screen.Pages.GetCell(pt).IsDirty()
@@ -1,17 +1,26 @@
 package terminal
 
 import (
+	"bytes"
+	"maps"
 	"slices"
 	"testing"
 
 	"github.com/hnimtadd/termio/logger"
+	"github.com/hnimtadd/termio/terminal/charsets"
 	"github.com/hnimtadd/termio/terminal/color"
 	"github.com/hnimtadd/termio/terminal/coordinate"
 	"github.com/hnimtadd/termio/terminal/core"
+	pagepkg "github.com/hnimtadd/termio/terminal/page"
 	"github.com/hnimtadd/termio/terminal/point"
+	"github.com/hnimtadd/termio/terminal/screen"
+	"github.com/hnimtadd/termio/terminal/sequences/csi"
+	"github.com/hnimtadd/termio/terminal/sequences/osc"
 	"github.com/hnimtadd/termio/terminal/sgr"
 	"github.com/hnimtadd/termio/terminal/size"
+	"github.com/hnimtadd/termio/terminal/style"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestTerminal_InputWithNoControlCharacters(t *testing.T) {
@@ -214,3 +223,635 @@ func TestTerminal_PrintSingleVeryLongLine(t *testing.T) {
 		term.Print('x')
 	}
 }
+
+func TestTerminal_TitleStack(t *testing.T) {
+	term := NewTerminal(Options{
+		Cols:   10,
+		Rows:   5,
+		Modes:  core.ModePacked,
+		Logger: logger.DefaultLogger,
+	})
+
+	// Only the window title is pushed, the icon name is kept as-is.
+	term.SetTitle("first")
+	term.SetIconName("icon")
+	term.PushTitle(osc.TitleTargetWindow)
+	term.SetTitle("second")
+	term.SetIconName("other")
+	term.PopTitle(osc.TitleTargetBoth)
+	assert.Equal(t, "first", term.GetTitle())
+	assert.Equal(t, "other", term.GetIconName())
+
+	// The stack is bounded, the oldest entries are dropped.
+	for i := range maxTitleStackSize + 2 {
+		term.SetTitle(string(rune('a' + i)))
+		term.PushTitle(osc.TitleTargetBoth)
+	}
+	for range maxTitleStackSize + 2 {
+		term.PopTitle(osc.TitleTargetBoth)
+	}
+	assert.Equal(t, "c", term.GetTitle())
+}
+
+// Write each line at the start of the rows from the top of the screen.
+func printLines(term *Terminal, lines ...string) {
+	for y, line := range lines {
+		term.SetCursorPosition(uint16(y+1), 1)
+		for _, c := range line {
+			term.Print(uint32(c))
+		}
+	}
+}
+
+// Print the lines at the cursor as a program would, separated by CR LF so
+// that the screen scrolls.
+func writeLines(term *Terminal, lines ...string) {
+	for i, line := range lines {
+		if i > 0 {
+			term.CarriageReturn()
+			term.LineFeed()
+		}
+		for _, c := range line {
+			term.Print(uint32(c))
+		}
+	}
+}
+
+func TestTerminal_SetTopAndBottomMargin(t *testing.T) {
+	term := NewTerminal(Options{
+		Cols:   5,
+		Rows:   5,
+		Modes:  maps.Clone(core.ModePacked),
+		Logger: logger.DefaultLogger,
+	})
+	printLines(term, "A", "B", "C", "D", "E")
+
+	// Setting the margins moves the cursor to the home position.
+	term.SetTopAndBottomMargin(2, 4)
+	assert.Equal(t, size.CellCountInt(0), term.Screen.Cursor.X)
+	assert.Equal(t, size.CellCountInt(0), term.Screen.Cursor.Y)
+
+	// Only the rows within the margins scroll.
+	term.SetCursorPosition(4, 1)
+	term.Index()
+	assert.Equal(t, "A\nC\nD\n\nE", term.PlainString())
+	assert.Equal(t, size.CellCountInt(3), term.Screen.Cursor.Y)
+
+	term.SetCursorPosition(2, 1)
+	term.ReverseIndex()
+	assert.Equal(t, "A\n\nC\nD\nE", term.PlainString())
+	assert.Equal(t, size.CellCountInt(1), term.Screen.Cursor.Y)
+
+	// Invalid margins are ignored, no parameters reset the margins.
+	term.SetTopAndBottomMargin(3, 3)
+	assert.Equal(t, size.CellCountInt(1), term.scrollingRegion.top)
+	assert.Equal(t, size.CellCountInt(3), term.scrollingRegion.bottom)
+	term.SetTopAndBottomMargin(0, 0)
+	assert.Equal(t, size.CellCountInt(0), term.scrollingRegion.top)
+	assert.Equal(t, size.CellCountInt(4), term.scrollingRegion.bottom)
+}
+
+func TestTerminal_SetLeftAndRightMargin(t *testing.T) {
+	term := NewTerminal(Options{
+		Cols:   5,
+		Rows:   3,
+		Modes:  maps.Clone(core.ModePacked),
+		Logger: logger.DefaultLogger,
+	})
+	printLines(term, "abcde", "fghij", "klmno")
+
+	// The margins are ignored without DECLRMM.
+	term.SetLeftAndRightMargin(2, 4)
+	assert.Equal(t, size.CellCountInt(0), term.scrollingRegion.left)
+	assert.Equal(t, size.CellCountInt(4), term.scrollingRegion.right)
+
+	term.SetMode(core.ModeEnableLeftAndRightMargin, true)
+	term.SetLeftAndRightMargin(2, 4)
+	assert.Equal(t, size.CellCountInt(1), term.scrollingRegion.left)
+	assert.Equal(t, size.CellCountInt(3), term.scrollingRegion.right)
+
+	// Only the cells within the margins scroll.
+	term.SetCursorPosition(3, 2)
+	term.Index()
+	assert.Equal(t, "aghie\nflmnj\nk   o", term.PlainString())
+
+	// Resetting DECLRMM resets the margins.
+	term.SetMode(core.ModeEnableLeftAndRightMargin, false)
+	assert.Equal(t, size.CellCountInt(0), term.scrollingRegion.left)
+	assert.Equal(t, size.CellCountInt(4), term.scrollingRegion.right)
+}
+
+func TestTerminal_OriginMode(t *testing.T) {
+	term := NewTerminal(Options{
+		Cols:   10,
+		Rows:   10,
+		Modes:  maps.Clone(core.ModePacked),
+		Logger: logger.DefaultLogger,
+	})
+	term.SetMode(core.ModeEnableLeftAndRightMargin, true)
+	term.SetTopAndBottomMargin(3, 6)
+	term.SetLeftAndRightMargin(2, 8)
+
+	// Setting origin mode moves the cursor to the top-left of the region.
+	term.SetMode(core.ModeOrigin, true)
+	assert.Equal(t, size.CellCountInt(1), term.Screen.Cursor.X)
+	assert.Equal(t, size.CellCountInt(2), term.Screen.Cursor.Y)
+
+	// Positions are relative to the region and limited to it.
+	term.SetCursorPosition(2, 3)
+	assert.Equal(t, size.CellCountInt(3), term.Screen.Cursor.X)
+	assert.Equal(t, size.CellCountInt(3), term.Screen.Cursor.Y)
+	row, col := term.CursorReportPosition()
+	assert.Equal(t, 2, row)
+	assert.Equal(t, 3, col)
+
+	term.SetCursorPosition(10, 10)
+	assert.Equal(t, size.CellCountInt(7), term.Screen.Cursor.X)
+	assert.Equal(t, size.CellCountInt(5), term.Screen.Cursor.Y)
+
+	// Resetting origin mode moves the cursor to the top-left of the screen.
+	term.SetMode(core.ModeOrigin, false)
+	assert.Equal(t, size.CellCountInt(0), term.Screen.Cursor.X)
+	assert.Equal(t, size.CellCountInt(0), term.Screen.Cursor.Y)
+}
+
+func TestTerminal_AlternateScreen(t *testing.T) {
+	term := NewTerminal(Options{
+		Cols:   5,
+		Rows:   3,
+		Modes:  maps.Clone(core.ModePacked),
+		Logger: logger.DefaultLogger,
+	})
+	writeLines(term, "A", "B", "C", "D")
+	assert.Equal(t, ScreenTypePrimary, term.ActiveScreen())
+
+	// The alternate screen starts empty, the cursor keeps its position.
+	term.SetMode(core.ModeAltScreenLegacy, true)
+	assert.Equal(t, ScreenTypeAlternate, term.ActiveScreen())
+	assert.Equal(t, "", term.PlainString())
+	assert.Equal(t, size.CellCountInt(1), term.Screen.Cursor.X)
+	assert.Equal(t, size.CellCountInt(2), term.Screen.Cursor.Y)
+
+	// Lines scrolled off the alternate screen are lost.
+	term.CarriageReturn()
+	term.LineFeed()
+	writeLines(term, "x", "y", "z", "w")
+	var screenString bytes.Buffer
+	require.NoError(t, term.Screen.DumpString(&screenString, point.TagScreen))
+	assert.Equal(t, "y\nz\nw", screenString.String())
+
+	// Switching back shows the primary screen as it was, 47 keeps the
+	// content of the alternate screen.
+	term.SetMode(core.ModeAltScreenLegacy, false)
+	assert.Equal(t, ScreenTypePrimary, term.ActiveScreen())
+	assert.Equal(t, "B\nC\nD", term.PlainString())
+	term.SetMode(core.ModeAltScreenLegacy, true)
+	assert.Equal(t, "y\nz\nw", term.PlainString())
+
+	// 1047 clears the alternate screen when leaving it.
+	term.SetMode(core.ModeAltScreen, false)
+	assert.Equal(t, "B\nC\nD", term.PlainString())
+	term.SetMode(core.ModeAltScreen, true)
+	assert.Equal(t, "", term.PlainString())
+}
+
+func TestTerminal_AlternateScreenSaveCursor(t *testing.T) {
+	term := NewTerminal(Options{
+		Cols:   5,
+		Rows:   3,
+		Modes:  maps.Clone(core.ModePacked),
+		Logger: logger.DefaultLogger,
+	})
+	writeLines(term, "A", "B")
+	term.Screen.SetAttribute(sgr.Attribute{Type: sgr.AttributeTypeBold})
+
+	// 1049 clears the alternate screen when entering it.
+	term.SetMode(core.ModeAltScreenLegacy, true)
+	term.CarriageReturn()
+	writeLines(term, "x")
+	term.SetMode(core.ModeAltScreenLegacy, false)
+	term.SetMode(core.ModeAltScreenSaveCursor, true)
+	assert.Equal(t, ScreenTypeAlternate, term.ActiveScreen())
+	assert.Equal(t, "", term.PlainString())
+
+	term.Screen.SetAttribute(sgr.Attribute{Type: sgr.AttributeTypeUnset})
+	term.SetCursorPosition(3, 4)
+
+	// The cursor and its style are restored when leaving.
+	term.SetMode(core.ModeAltScreenSaveCursor, false)
+	assert.Equal(t, ScreenTypePrimary, term.ActiveScreen())
+	assert.Equal(t, "A\nB", term.PlainString())
+	assert.Equal(t, size.CellCountInt(1), term.Screen.Cursor.X)
+	assert.Equal(t, size.CellCountInt(1), term.Screen.Cursor.Y)
+	assert.True(t, term.Screen.Cursor.Style.Bold)
+}
+
+func TestTerminal_FullReset(t *testing.T) {
+	term := NewTerminal(Options{
+		Cols:   5,
+		Rows:   3,
+		Modes:  maps.Clone(core.ModePacked),
+		Logger: logger.DefaultLogger,
+	})
+	writeLines(term, "A", "B", "C", "D")
+	term.SetMode(core.ModeAltScreenLegacy, true)
+	writeLines(term, "x")
+
+	term.FullReset()
+	assert.Equal(t, ScreenTypePrimary, term.ActiveScreen())
+	assert.Equal(t, "", term.PlainString())
+	assert.Equal(t, size.CellCountInt(0), term.Screen.Cursor.X)
+	assert.Equal(t, size.CellCountInt(0), term.Screen.Cursor.Y)
+
+	printLines(term, "E")
+	assert.Equal(t, "E", term.PlainString())
+}
+
+func TestTerminal_SoftReset(t *testing.T) {
+	term := NewTerminal(Options{
+		Cols:   5,
+		Rows:   3,
+		Modes:  maps.Clone(core.ModePacked),
+		Logger: logger.DefaultLogger,
+	})
+	writeLines(term, "A", "B", "C", "D")
+	term.SetMode(core.ModeInsert, true)
+	term.SetMode(core.ModeWraparound, false)
+	term.SetMode(core.ModeEnableLeftAndRightMargin, true)
+	term.SetTopAndBottomMargin(1, 2)
+	term.SetLeftAndRightMargin(2, 4)
+	term.SetMode(core.ModeOrigin, true)
+	term.SetCursorPosition(2, 2)
+	term.Screen.SetAttribute(sgr.Attribute{Type: sgr.AttributeTypeBold})
+	term.ConfigureCharset(charsets.SlotG0, charsets.CharsetDECSpecial)
+	term.SaveCursor()
+
+	term.SoftReset()
+	assert.Equal(t, "B\nC\nD", term.PlainString())
+	assert.Equal(t, size.CellCountInt(2), term.Screen.Cursor.X)
+	assert.Equal(t, size.CellCountInt(1), term.Screen.Cursor.Y)
+	assert.False(t, term.Modes.Get(core.ModeInsert))
+	assert.False(t, term.Modes.Get(core.ModeOrigin))
+	assert.True(t, term.Modes.Get(core.ModeWraparound))
+	assert.False(t, term.Modes.Get(core.ModeEnableLeftAndRightMargin))
+	assert.Equal(t, ScrollingRegion{top: 0, bottom: 2, left: 0, right: 4}, *term.scrollingRegion)
+	assert.Equal(t, style.Style{}, term.Screen.Cursor.Style)
+	assert.Equal(t, charsets.NewState(), term.Screen.Charset)
+	assert.Nil(t, term.Screen.SavedCursor)
+
+	// The scrollback is kept.
+	var dump bytes.Buffer
+	require.NoError(t, term.Screen.DumpString(&dump, point.TagScreen))
+	assert.Equal(t, "A\nB\nC\nD", dump.String())
+}
+
+func TestTerminal_SaveCursor(t *testing.T) {
+	term := NewTerminal(Options{
+		Cols:   10,
+		Rows:   5,
+		Modes:  maps.Clone(core.ModePacked),
+		Logger: logger.DefaultLogger,
+	})
+	term.SetTopAndBottomMargin(2, 4)
+	term.SetMode(core.ModeOrigin, true)
+	term.SetCursorPosition(2, 3)
+	term.Screen.SetAttribute(sgr.Attribute{Type: sgr.AttributeTypeBold})
+	term.ConfigureCharset(charsets.SlotG0, charsets.CharsetDECSpecial)
+	term.SaveCursor()
+
+	term.SetMode(core.ModeOrigin, false)
+	term.SetCursorPosition(5, 10)
+	term.Screen.SetAttribute(sgr.Attribute{Type: sgr.AttributeTypeUnset})
+	term.ConfigureCharset(charsets.SlotG0, charsets.CharsetUTF8)
+
+	term.RestoreCursor()
+	assert.Equal(t, size.CellCountInt(2), term.Screen.Cursor.X)
+	assert.Equal(t, size.CellCountInt(2), term.Screen.Cursor.Y)
+	assert.True(t, term.Screen.Cursor.Style.Bold)
+	assert.True(t, term.Modes.Get(core.ModeOrigin))
+	term.Print('q')
+	assert.Equal(t, "\n\n  ─", term.PlainString())
+
+	// Restoring again gives the same state, the record is kept.
+	term.SetCursorPosition(1, 1)
+	term.RestoreCursor()
+	assert.Equal(t, size.CellCountInt(2), term.Screen.Cursor.X)
+	assert.Equal(t, size.CellCountInt(2), term.Screen.Cursor.Y)
+
+	// The character sets are brought to the alternate screen but it has its
+	// own record, restoring without a saved cursor resets the state.
+	term.SetMode(core.ModeAltScreenLegacy, true)
+	assert.Equal(t, charsets.CharsetDECSpecial, term.Screen.Charset.Charsets[charsets.SlotG0])
+	term.RestoreCursor()
+	assert.Equal(t, size.CellCountInt(0), term.Screen.Cursor.X)
+	assert.Equal(t, size.CellCountInt(0), term.Screen.Cursor.Y)
+	assert.False(t, term.Screen.Cursor.Style.Bold)
+	assert.False(t, term.Modes.Get(core.ModeOrigin))
+	term.Print('q')
+	assert.Equal(t, "q", term.PlainString())
+}
+
+func TestTerminal_ScrollUp(t *testing.T) {
+	term := NewTerminal(Options{
+		Cols:   5,
+		Rows:   4,
+		Modes:  maps.Clone(core.ModePacked),
+		Logger: logger.DefaultLogger,
+	})
+	writeLines(term, "A", "B", "C", "D")
+	term.SetCursorPosition(2, 2)
+
+	// The lines go to the scrollback, the cursor doesn't move.
+	term.ScrollUp(2)
+	assert.Equal(t, "C\nD", term.PlainString())
+	assert.Equal(t, size.CellCountInt(1), term.Screen.Cursor.X)
+	assert.Equal(t, size.CellCountInt(1), term.Screen.Cursor.Y)
+	var screenString bytes.Buffer
+	require.NoError(t, term.Screen.DumpString(&screenString, point.TagScreen))
+	assert.Equal(t, "A\nB\nC\nD", screenString.String())
+
+	// Within a region, the lines scrolled out are lost.
+	printLines(term, "A", "B", "C", "D")
+	term.SetTopAndBottomMargin(2, 3)
+	term.SetCursorPosition(4, 1)
+	term.ScrollUp(1)
+	assert.Equal(t, "A\nC\n\nD", term.PlainString())
+	assert.Equal(t, size.CellCountInt(3), term.Screen.Cursor.Y)
+
+	// Scrolling more than the region clears it.
+	term.ScrollUp(10)
+	assert.Equal(t, "A\n\n\nD", term.PlainString())
+}
+
+func TestTerminal_ScrollDown(t *testing.T) {
+	term := NewTerminal(Options{
+		Cols:   5,
+		Rows:   4,
+		Modes:  maps.Clone(core.ModePacked),
+		Logger: logger.DefaultLogger,
+	})
+	printLines(term, "A", "B", "C", "D")
+	term.SetCursorPosition(3, 2)
+
+	term.ScrollDown(1)
+	assert.Equal(t, "\nA\nB\nC", term.PlainString())
+	assert.Equal(t, size.CellCountInt(1), term.Screen.Cursor.X)
+	assert.Equal(t, size.CellCountInt(2), term.Screen.Cursor.Y)
+
+	// Only the lines of the region move.
+	printLines(term, "A", "B", "C", "D")
+	term.SetTopAndBottomMargin(2, 3)
+	term.ScrollDown(1)
+	assert.Equal(t, "A\n\nB\nD", term.PlainString())
+}
+
+func TestTerminal_EraseChars(t *testing.T) {
+	term := NewTerminal(Options{
+		Cols:   10,
+		Rows:   2,
+		Modes:  maps.Clone(core.ModePacked),
+		Logger: logger.DefaultLogger,
+	})
+	printLines(term, "abcdefghij", "a橋cd")
+
+	// The rest of the line doesn't move.
+	term.SetCursorPosition(1, 3)
+	term.EraseChars(2)
+	assert.Equal(t, "ab  efghij\na橋cd", term.PlainString())
+	assert.Equal(t, size.CellCountInt(2), term.Screen.Cursor.X)
+
+	// Zero erases one character, the erase stops at the end of the line.
+	term.EraseChars(0)
+	assert.Equal(t, "ab  efghij\na橋cd", term.PlainString())
+	term.SetCursorPosition(1, 9)
+	term.EraseChars(5)
+	assert.Equal(t, "ab  efgh\na橋cd", term.PlainString())
+
+	// Wide characters are never split.
+	term.SetCursorPosition(2, 3)
+	term.EraseChars(1)
+	assert.Equal(t, "ab  efgh\na  cd", term.PlainString())
+	printLines(term, "abcdefghij", "a橋cd")
+	term.SetCursorPosition(2, 1)
+	term.EraseChars(2)
+	assert.Equal(t, "abcdefghij\n   cd", term.PlainString())
+
+	// The erased cells get the background color.
+	term.Screen.SetAttribute(sgr.Attribute{Type: sgr.AttributeTypePaletteBg, PaletteBg: 1})
+	term.SetCursorPosition(1, 1)
+	term.EraseChars(1)
+	cell := term.Screen.Pages.GetCell(point.Point{Tag: point.TagActive})
+	assert.Equal(t, pagepkg.ContentTagBGColorPalette, cell.Cell.ContentTag)
+}
+
+func TestTerminal_PrintWideWrap(t *testing.T) {
+	term := NewTerminal(Options{
+		Cols:   5,
+		Rows:   3,
+		Modes:  maps.Clone(core.ModePacked),
+		Logger: logger.DefaultLogger,
+	})
+
+	// A wide character that doesn't fit at the end of the line wraps as a
+	// whole.
+	for range 3 {
+		term.Print('橋')
+	}
+	assert.Equal(t, "橋橋\n橋", term.PlainString())
+	assert.Equal(t, size.CellCountInt(2), term.Screen.Cursor.X)
+}
+
+func TestTerminal_PrintWideWithoutWraparound(t *testing.T) {
+	term := NewTerminal(Options{
+		Cols:   5,
+		Rows:   3,
+		Modes:  maps.Clone(core.ModePacked),
+		Logger: logger.DefaultLogger,
+	})
+	term.SetMode(core.ModeWraparound, false)
+
+	// The wide character doesn't fit and is dropped.
+	printLines(term, "abcd")
+	term.Print('橋')
+	assert.Equal(t, "abcd", term.PlainString())
+	assert.Equal(t, size.CellCountInt(4), term.Screen.Cursor.X)
+
+	// It fits exactly at the end of the line.
+	term.SetCursorPosition(1, 4)
+	term.Print('橋')
+	assert.Equal(t, "abc橋", term.PlainString())
+	assert.True(t, term.Screen.Cursor.PendingWrap)
+}
+
+func TestTerminal_Tabstops(t *testing.T) {
+	term := NewTerminal(Options{
+		Cols:   20,
+		Rows:   2,
+		Modes:  maps.Clone(core.ModePacked),
+		Logger: logger.DefaultLogger,
+	})
+	assert.Equal(t, []size.CellCountInt{8, 16}, term.Tabstops())
+
+	// Tabs stop at the right margin, backward tabs at the left margin.
+	term.SetCursorTabRight(5)
+	assert.Equal(t, size.CellCountInt(19), term.Screen.Cursor.X)
+	term.SetCursorTabLeft(1)
+	assert.Equal(t, size.CellCountInt(16), term.Screen.Cursor.X)
+	term.SetCursorTabLeft(0)
+	assert.Equal(t, size.CellCountInt(8), term.Screen.Cursor.X)
+	term.SetCursorTabLeft(2)
+	assert.Equal(t, size.CellCountInt(0), term.Screen.Cursor.X)
+
+	term.SetCursorPosition(1, 4)
+	term.TabSet()
+	term.SetCursorPosition(1, 9)
+	term.TabClear(csi.TBCModeCurrent)
+	assert.Equal(t, []size.CellCountInt{3, 16}, term.Tabstops())
+
+	// Resizing keeps the tabstops, the new columns get the default ones.
+	term.Resize(40, 2)
+	assert.Equal(t, []size.CellCountInt{3, 16, 24, 32}, term.Tabstops())
+	term.Resize(10, 2)
+	assert.Equal(t, []size.CellCountInt{3}, term.Tabstops())
+
+	term.TabClear(csi.TBCModeAll)
+	assert.Empty(t, term.Tabstops())
+	term.SetCursorPosition(1, 1)
+	term.SetCursorTabRight(1)
+	assert.Equal(t, size.CellCountInt(9), term.Screen.Cursor.X)
+
+	term.TabReset()
+	assert.Equal(t, []size.CellCountInt{8}, term.Tabstops())
+}
+
+func TestTerminal_TabstopsLeftAndRightMargin(t *testing.T) {
+	term := NewTerminal(Options{
+		Cols:   20,
+		Rows:   2,
+		Modes:  maps.Clone(core.ModePacked),
+		Logger: logger.DefaultLogger,
+	})
+	term.SetMode(core.ModeEnableLeftAndRightMargin, true)
+	term.SetLeftAndRightMargin(1, 6)
+
+	// Inside the margins, tabs stop at the right margin.
+	term.SetCursorPosition(1, 2)
+	term.SetCursorTabRight(1)
+	assert.Equal(t, size.CellCountInt(5), term.Screen.Cursor.X)
+
+	// Right of the margin, tabs move to the next tabstop or the last column.
+	term.SetCursorPosition(1, 7)
+	term.SetCursorTabRight(1)
+	assert.Equal(t, size.CellCountInt(8), term.Screen.Cursor.X)
+	term.SetCursorTabRight(2)
+	assert.Equal(t, size.CellCountInt(19), term.Screen.Cursor.X)
+}
+
+func TestTerminal_SetCursorRowAndCol(t *testing.T) {
+	term := NewTerminal(Options{
+		Cols:   10,
+		Rows:   10,
+		Modes:  maps.Clone(core.ModePacked),
+		Logger: logger.DefaultLogger,
+	})
+	term.SetCursorPosition(3, 4)
+
+	// VPA and HPA only change one coordinate, 0 is the same as 1 and the
+	// cursor stays on the screen.
+	term.SetCursorRow(6)
+	assert.Equal(t, size.CellCountInt(3), term.Screen.Cursor.X)
+	assert.Equal(t, size.CellCountInt(5), term.Screen.Cursor.Y)
+	term.SetCursorCol(0)
+	assert.Equal(t, size.CellCountInt(0), term.Screen.Cursor.X)
+	assert.Equal(t, size.CellCountInt(5), term.Screen.Cursor.Y)
+	term.SetCursorRow(20)
+	assert.Equal(t, size.CellCountInt(9), term.Screen.Cursor.Y)
+
+	// VPR and HPR move from the cursor, the margins don't stop them
+	// without origin mode.
+	term.SetTopAndBottomMargin(2, 5)
+	term.SetCursorPosition(4, 2)
+	term.SetCursorRowRelative(3)
+	assert.Equal(t, size.CellCountInt(1), term.Screen.Cursor.X)
+	assert.Equal(t, size.CellCountInt(6), term.Screen.Cursor.Y)
+	term.SetCursorColRelative(0)
+	assert.Equal(t, size.CellCountInt(2), term.Screen.Cursor.X)
+	term.SetCursorColRelative(100)
+	assert.Equal(t, size.CellCountInt(9), term.Screen.Cursor.X)
+	assert.Equal(t, size.CellCountInt(6), term.Screen.Cursor.Y)
+}
+
+func TestTerminal_SetCursorRowAndColOriginMode(t *testing.T) {
+	term := NewTerminal(Options{
+		Cols:   10,
+		Rows:   10,
+		Modes:  maps.Clone(core.ModePacked),
+		Logger: logger.DefaultLogger,
+	})
+	term.SetMode(core.ModeEnableLeftAndRightMargin, true)
+	term.SetTopAndBottomMargin(3, 6)
+	term.SetLeftAndRightMargin(2, 8)
+	term.SetMode(core.ModeOrigin, true)
+
+	// Positions are relative to the region and limited to it.
+	term.SetCursorRow(2)
+	assert.Equal(t, size.CellCountInt(1), term.Screen.Cursor.X)
+	assert.Equal(t, size.CellCountInt(3), term.Screen.Cursor.Y)
+	term.SetCursorCol(3)
+	assert.Equal(t, size.CellCountInt(3), term.Screen.Cursor.X)
+	assert.Equal(t, size.CellCountInt(3), term.Screen.Cursor.Y)
+	term.SetCursorRow(10)
+	assert.Equal(t, size.CellCountInt(5), term.Screen.Cursor.Y)
+	term.SetCursorCol(10)
+	assert.Equal(t, size.CellCountInt(7), term.Screen.Cursor.X)
+
+	// Relative moves stop at the margins.
+	term.SetCursorPosition(1, 1)
+	term.SetCursorRowRelative(2)
+	assert.Equal(t, size.CellCountInt(1), term.Screen.Cursor.X)
+	assert.Equal(t, size.CellCountInt(4), term.Screen.Cursor.Y)
+	term.SetCursorRowRelative(5)
+	assert.Equal(t, size.CellCountInt(5), term.Screen.Cursor.Y)
+	term.SetCursorColRelative(4)
+	assert.Equal(t, size.CellCountInt(5), term.Screen.Cursor.X)
+	term.SetCursorColRelative(4)
+	assert.Equal(t, size.CellCountInt(7), term.Screen.Cursor.X)
+	assert.Equal(t, size.CellCountInt(5), term.Screen.Cursor.Y)
+}
+
+func TestTerminal_CursorStyle(t *testing.T) {
+	term := NewTerminal(Options{
+		Cols:   10,
+		Rows:   5,
+		Modes:  maps.Clone(core.ModePacked),
+		Logger: logger.DefaultLogger,
+	})
+	assert.Equal(t, screen.CursorShapeBlock, term.Screen.Cursor.Shape)
+	assert.True(t, term.Screen.Cursor.Blinking)
+	assert.True(t, term.Screen.Cursor.Visible)
+
+	term.SetCursorStyle(csi.CursorStyleSteadyBar)
+	term.SetMode(core.ModeCursorVisible, false)
+	assert.Equal(t, screen.CursorShapeBar, term.Screen.Cursor.Shape)
+	assert.False(t, term.Screen.Cursor.Blinking)
+	assert.False(t, term.Screen.Cursor.Visible)
+
+	// The cursor looks the same on the alternate screen.
+	term.SetMode(core.ModeAltScreenSaveCursor, true)
+	assert.Equal(t, screen.CursorShapeBar, term.Screen.Cursor.Shape)
+	assert.False(t, term.Screen.Cursor.Visible)
+	term.SetCursorStyle(csi.CursorStyleBlinkingUnderline)
+	term.SetMode(core.ModeAltScreenSaveCursor, false)
+	assert.Equal(t, screen.CursorShapeUnderline, term.Screen.Cursor.Shape)
+	assert.True(t, term.Screen.Cursor.Blinking)
+
+	// DECSTR shows the cursor but keeps its shape.
+	term.SoftReset()
+	assert.True(t, term.Screen.Cursor.Visible)
+	assert.True(t, term.Modes.Get(core.ModeCursorVisible))
+	assert.Equal(t, screen.CursorShapeUnderline, term.Screen.Cursor.Shape)
+
+	term.SetCursorStyle(csi.CursorStyleDefault)
+	assert.Equal(t, screen.CursorShapeBlock, term.Screen.Cursor.Shape)
+	assert.True(t, term.Screen.Cursor.Blinking)
+}
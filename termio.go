@@ -3,12 +3,16 @@ package termio
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"runtime/debug"
 
 	"github.com/hnimtadd/termio/logger"
 	"github.com/hnimtadd/termio/terminal"
+	"github.com/hnimtadd/termio/terminal/color"
 	"github.com/hnimtadd/termio/terminal/core"
+	"github.com/hnimtadd/termio/terminal/input"
 	"github.com/hnimtadd/termio/terminal/point"
+	"github.com/hnimtadd/termio/terminal/screen"
 	"github.com/hnimtadd/termio/terminal/size"
 	"github.com/hnimtadd/termio/terminal/stream"
 )
@@ -26,12 +30,42 @@ type TerminalIO struct {
 	// Event manager for handling callbacks
 	eventManager *EventManager
 
+	// The replies to the program when no response writer is configured.
+	responses *responseQueue
+
+	// Where the replies and the focus reports are written.
+	responseWriter io.Writer
+
+	// Whether the terminal window has the focus, see SetFocused.
+	focused bool
+
 	logger logger.Logger
 }
 
 type Options struct {
 	Rows, Cols int
 	Logger     logger.Logger
+
+	// Where replies to the program running in the terminal are written,
+	// usually the PTY. If nil, the replies are delivered on the channel
+	// returned by TerminalIO.Responses instead.
+	ResponseWriter io.Writer
+
+	// The clipboard programs access with OSC 52. OSC 52 is ignored if this
+	// is nil.
+	Clipboard Clipboard
+
+	// The default colors, programs can change them at runtime with OSC 4,
+	// 10, 11 and 12. Unset colors use the built-in defaults, the cursor
+	// follows the foreground color by default.
+	Palette         *color.Palette
+	ForegroundColor *color.RGB
+	BackgroundColor *color.RGB
+	CursorColor     *color.RGB
+
+	// The identity reported to device attributes requests (DA1, DA2 and
+	// DA3). If nil, terminal.DefaultDeviceIdentity is used.
+	DeviceIdentity *terminal.DeviceIdentity
 }
 
 // Initialize the termio state.
@@ -45,18 +79,44 @@ func NewTerminalIO(opts Options) *TerminalIO {
 	// Create a new terminal instance
 	term := terminal.NewTerminal(
 		terminal.Options{
-			Rows:   opts.Rows,
-			Cols:   opts.Cols,
-			Modes:  modes,
-			Logger: opts.Logger,
+			Rows:           opts.Rows,
+			Cols:           opts.Cols,
+			Modes:          modes,
+			Palette:        opts.Palette,
+			DeviceIdentity: opts.DeviceIdentity,
+			Logger:         opts.Logger,
 		},
 	)
+	palette := term.Palette()
+	foreground := palette[color.ColorTypeWhite]
+	if opts.ForegroundColor != nil {
+		foreground = *opts.ForegroundColor
+	}
+	background := palette[color.ColorTypeBlack]
+	if opts.BackgroundColor != nil {
+		background = *opts.BackgroundColor
+	}
+
+	// Replies go to the configured writer, or are queued for Responses.
+	var responses *responseQueue
+	responseWriter := opts.ResponseWriter
+	if responseWriter == nil {
+		responses = newResponseQueue(opts.Logger)
+		responseWriter = responses
+	}
 
 	// Create our stream handler.
 	handler := &StreamHandler{
-		terminal:     term,
-		logger:       opts.Logger,
-		eventManager: NewEventManager(),
+		terminal:               term,
+		defaultForegroundColor: foreground,
+		defaultBackgroundColor: background,
+		defaultCursorColor:     opts.CursorColor,
+		foregroundColor:        foreground,
+		backgroundColor:        background,
+		responseWriter:         responseWriter,
+		clipboard:              opts.Clipboard,
+		logger:                 opts.Logger,
+		eventManager:           NewEventManager(),
 	}
 	termio := &TerminalIO{
 		terminal: term,
@@ -64,8 +124,11 @@ func NewTerminalIO(opts Options) *TerminalIO {
 			handler,
 			opts.Logger,
 		),
-		eventManager: handler.eventManager,
-		logger:       opts.Logger,
+		eventManager:   handler.eventManager,
+		responses:      responses,
+		responseWriter: responseWriter,
+		focused:        true,
+		logger:         opts.Logger,
 	}
 	return termio
 }
@@ -113,7 +176,7 @@ func (t *TerminalIO) Process(c byte) (err error) {
 }
 
 // ProcessForOutput processes PTY input and returns bytes that should be written to stdout
-// This is the proper way to handle terminal emulation - process escape sequences 
+// This is the proper way to handle terminal emulation - process escape sequences
 // and return the current terminal state that should be displayed
 func (t *TerminalIO) ProcessForOutput(buf []byte) ([]byte, error) {
 	// Process the input through termio to update internal state
@@ -121,7 +184,7 @@ func (t *TerminalIO) ProcessForOutput(buf []byte) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
-	
+
 	// For now, return the input as-is but let termio process it internally
 	// This maintains the proper terminal state while allowing raw sequences through
 	return buf, nil
@@ -134,11 +197,11 @@ func (t *TerminalIO) DumpString() string {
 func (t *TerminalIO) DumpStringWithCursor() string {
 	// Get the plain content
 	content := t.terminal.PlainString()
-	
+
 	// Get cursor position (1-based for ANSI sequences)
 	cursorX := int(t.terminal.Screen.Cursor.X) + 1
 	cursorY := int(t.terminal.Screen.Cursor.Y) + 1
-	
+
 	// Add cursor positioning after content
 	return content + fmt.Sprintf("\033[%d;%dH", cursorY, cursorX)
 }
@@ -157,16 +220,145 @@ func (t *TerminalIO) Write(p []byte) (n int, err error) {
 	return len(p), nil
 }
 
+// Close releases the resources of the terminal. It must be called when the
+// terminal is no longer used if Responses was called, to stop the goroutine
+// delivering the replies.
 func (t *TerminalIO) Close() error {
-	// Currently there are no resources that need explicit cleanup
-	// The terminal and stream are managed by Go's garbage collector
-	// This method is here for interface compatibility and future resource management
+	// The terminal and stream are managed by Go's garbage collector, only
+	// the response queue needs to be stopped.
+	if t.responses != nil {
+		t.responses.Close()
+	}
 	if t.logger != nil {
 		t.logger.Info("TerminalIO closed")
 	}
 	return nil
 }
 
+// Responses returns the replies to the program running in the terminal, such
+// as answers to device attribute, cursor position, color or clipboard
+// queries, in the order of the queries in the output. They must be written
+// to the PTY.
+//
+// This is only used when Options.ResponseWriter is nil, otherwise nil is
+// returned. The first call starts a goroutine delivering the replies, Close
+// must be called to stop it and closes the channel. Up to 1024 replies are
+// kept while the channel isn't read, the oldest are dropped beyond this, see
+// DroppedResponses.
+func (t *TerminalIO) Responses() <-chan []byte {
+	if t.responses == nil {
+		return nil
+	}
+	return t.responses.Channel()
+}
+
+// DroppedResponses returns the number of replies dropped because the
+// Responses channel wasn't read fast enough.
+func (t *TerminalIO) DroppedResponses() int {
+	if t.responses == nil {
+		return 0
+	}
+	return t.responses.Dropped()
+}
+
+// GetTitle returns the window title set by the application (OSC 0/2)
+func (t *TerminalIO) GetTitle() string {
+	return t.terminal.GetTitle()
+}
+
+// GetIconName returns the icon name set by the application (OSC 0/1)
+func (t *TerminalIO) GetIconName() string {
+	return t.terminal.GetIconName()
+}
+
+// ActiveScreen returns the screen currently shown, the alternate screen is
+// used by full-screen programs (modes 47, 1047 and 1049).
+func (t *TerminalIO) ActiveScreen() terminal.ScreenType {
+	return t.terminal.ActiveScreen()
+}
+
+// CursorShape returns the shape of the cursor set by the application
+// (DECSCUSR).
+func (t *TerminalIO) CursorShape() screen.CursorShape {
+	return t.terminal.Screen.Cursor.Shape
+}
+
+// CursorBlinking returns whether the application asked for a blinking
+// cursor (DECSCUSR).
+func (t *TerminalIO) CursorBlinking() bool {
+	return t.terminal.Screen.Cursor.Blinking
+}
+
+// CursorVisible returns whether the cursor is shown, programs hide it with
+// mode 25 (DECTCEM).
+func (t *TerminalIO) CursorVisible() bool {
+	return t.terminal.Screen.Cursor.Visible
+}
+
+// EncodeKey returns the bytes to write to the PTY for the key pressed by the
+// user, depending on the modes the application set (cursor keys, keypad,
+// modifyOtherKeys and the kitty keyboard protocol). Nil is returned if the
+// key produces nothing.
+func (t *TerminalIO) EncodeKey(event input.KeyEvent) []byte {
+	encoder := input.Encoder{
+		Modes:      t.terminal.Modes,
+		KittyFlags: t.terminal.Screen.KittyKeyboard.Current(),
+	}
+	return encoder.Encode(event)
+}
+
+// EncodeMouse returns the bytes to write to the PTY for the mouse event,
+// depending on the tracking mode and the format the application set (modes
+// 9, 1000, 1002, 1003 and 1005, 1006, 1015, 1016). Nil is returned if the
+// application doesn't track the mouse or that event.
+//
+// On the alternate screen the wheel is sent as the up and down arrows when
+// the application set the alternate scroll mode (1007) and doesn't track
+// the mouse.
+func (t *TerminalIO) EncodeMouse(event input.MouseEvent) []byte {
+	encoder := input.Encoder{
+		Modes:     t.terminal.Modes,
+		AltScreen: t.terminal.ActiveScreen() == terminal.ScreenTypeAlternate,
+	}
+	return encoder.EncodeMouse(event)
+}
+
+// EncodePaste returns the bytes to write to the PTY for the text pasted by
+// the user. The text is wrapped in ESC[200~ and ESC[201~ when the
+// application set the bracketed paste mode (2004), otherwise newlines are
+// sent as CR. Control characters are removed from the text so it can't
+// inject escape sequences or end the paste early.
+func (t *TerminalIO) EncodePaste(text string) []byte {
+	encoder := input.Encoder{Modes: t.terminal.Modes}
+	return encoder.EncodePaste(text)
+}
+
+// SetFocused tells the terminal whether its window has the focus. When the
+// application set the focus event mode (1004), ESC[I is sent when the
+// window gains the focus and ESC[O when it loses it. The terminal starts
+// focused and only changes of the focus are reported.
+func (t *TerminalIO) SetFocused(focused bool) {
+	if t.focused == focused {
+		return
+	}
+	t.focused = focused
+
+	encoder := input.Encoder{Modes: t.terminal.Modes}
+	report := encoder.EncodeFocus(focused)
+	if report == nil {
+		return
+	}
+	if _, err := t.responseWriter.Write(report); err != nil {
+		t.logger.Warn("failed to write focus report", "error", err)
+	}
+}
+
+// HyperlinkAt returns the hyperlink (OSC 8) under the given point and the
+// range of cells it covers, or nil if there is no hyperlink there.
+func (t *TerminalIO) HyperlinkAt(pt point.Point) *screen.HyperlinkRange {
+	return t.terminal.HyperlinkAt(pt)
+}
+
 // RegisterCallback registers a callback for a specific event type
 func (t *TerminalIO) RegisterCallback(eventType EventType, callback EventCallback) {
 	t.eventManager.RegisterCallback(eventType, callback)
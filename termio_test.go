@@ -1,9 +1,23 @@
 package termio
 
 import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
 	"testing"
 
 	"github.com/hnimtadd/termio/logger"
+	"github.com/hnimtadd/termio/terminal"
+	"github.com/hnimtadd/termio/terminal/color"
+	"github.com/hnimtadd/termio/terminal/coordinate"
+	"github.com/hnimtadd/termio/terminal/core"
+	"github.com/hnimtadd/termio/terminal/input"
+	"github.com/hnimtadd/termio/terminal/page"
+	"github.com/hnimtadd/termio/terminal/point"
+	"github.com/hnimtadd/termio/terminal/screen"
+	"github.com/hnimtadd/termio/terminal/size"
+	"github.com/hnimtadd/termio/terminal/style"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -192,4 +206,923 @@ func TestTerminalIOPanicRecovery(t *testing.T) {
 			_ = termio
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestTerminalIOWindowTitle(t *testing.T) {
+	termio := NewTerminalIO(Options{
+		Rows:   24,
+		Cols:   80,
+		Logger: logger.New(logger.Options{Buffer: io.Discard}),
+	})
+
+	var events []TitleEvent
+	termio.RegisterCallback(EventTypeTitle, func(event *Event) {
+		events = append(events, event.Data.(TitleEvent))
+	})
+
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b]0;both\x07")))
+	assert.Equal(t, "both", termio.GetTitle())
+	assert.Equal(t, "both", termio.GetIconName())
+
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b]2;vim\x1b\\")))
+	assert.Equal(t, "vim", termio.GetTitle())
+	assert.Equal(t, "both", termio.GetIconName())
+
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b]1;icon\x07")))
+	assert.Equal(t, "vim", termio.GetTitle())
+	assert.Equal(t, "icon", termio.GetIconName())
+
+	// Setting the same title again doesn't emit an event.
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b]1;icon\x07")))
+
+	assert.Equal(t, []TitleEvent{
+		{Title: "both", IconName: "both"},
+		{Title: "vim", IconName: "both"},
+		{Title: "vim", IconName: "icon"},
+	}, events)
+}
+
+func TestTerminalIOTitleStack(t *testing.T) {
+	termio := NewTerminalIO(Options{
+		Rows:   24,
+		Cols:   80,
+		Logger: logger.New(logger.Options{Buffer: io.Discard}),
+	})
+
+	var events []TitleEvent
+	termio.RegisterCallback(EventTypeTitle, func(event *Event) {
+		events = append(events, event.Data.(TitleEvent))
+	})
+
+	// shell sets the title, vim saves it, sets its own and restores it.
+	require.NoError(t, termio.ProcessOutput([]byte(
+		"\x1b]0;shell\x07\x1b[22;0t\x1b]2;vim\x07",
+	)))
+	assert.Equal(t, "vim", termio.GetTitle())
+
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b[23;0t")))
+	assert.Equal(t, "shell", termio.GetTitle())
+	assert.Equal(t, "shell", termio.GetIconName())
+	require.Len(t, events, 3)
+	assert.Equal(t, TitleEvent{Title: "shell", IconName: "shell"}, events[2])
+
+	// Popping an empty stack keeps the title.
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b[23t")))
+	assert.Equal(t, "shell", termio.GetTitle())
+	assert.Len(t, events, 3)
+}
+
+func TestTerminalIOReportPwd(t *testing.T) {
+	termio := NewTerminalIO(Options{
+		Rows:   24,
+		Cols:   80,
+		Logger: logger.New(logger.Options{Buffer: io.Discard}),
+	})
+
+	var events []PwdEvent
+	termio.RegisterCallback(EventTypePwd, func(event *Event) {
+		events = append(events, event.Data.(PwdEvent))
+	})
+
+	hostname, err := os.Hostname()
+	require.NoError(t, err)
+
+	require.NoError(t, termio.ProcessOutput([]byte(
+		"\x1b]7;file://"+hostname+"/home/user/my%20project\x07",
+	)))
+	assert.Equal(t, "/home/user/my project", termio.terminal.GetPwd())
+
+	// Reporting the same directory again doesn't emit an event.
+	require.NoError(t, termio.ProcessOutput([]byte(
+		"\x1b]7;file://"+hostname+"/home/user/my%20project\x1b\\",
+	)))
+
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b]7;file:///tmp\x07")))
+	assert.Equal(t, "/tmp", termio.terminal.GetPwd())
+
+	// A remote host is reported but doesn't change our pwd.
+	require.NoError(t, termio.ProcessOutput([]byte(
+		"\x1b]7;file://remote.example.com/srv/app\x07",
+	)))
+	assert.Equal(t, "/tmp", termio.terminal.GetPwd())
+
+	// Invalid URIs are ignored.
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b]7;http://host/x\x07")))
+	assert.Equal(t, "/tmp", termio.terminal.GetPwd())
+
+	assert.Equal(t, []PwdEvent{
+		{Host: hostname, Path: "/home/user/my project", Local: true},
+		{Host: "", Path: "/tmp", Local: true},
+		{Host: "remote.example.com", Path: "/srv/app", Local: false},
+	}, events)
+}
+
+func TestTerminalIOSemanticPrompt(t *testing.T) {
+	termio := NewTerminalIO(Options{
+		Rows:   5,
+		Cols:   20,
+		Logger: logger.New(logger.Options{Buffer: io.Discard}),
+	})
+
+	var prompts []PromptEvent
+	var starts []CommandStartEvent
+	var ends []CommandEndEvent
+	termio.RegisterCallback(EventTypePrompt, func(event *Event) {
+		prompts = append(prompts, event.Data.(PromptEvent))
+	})
+	termio.RegisterCallback(EventTypeCommandStart, func(event *Event) {
+		starts = append(starts, event.Data.(CommandStartEvent))
+	})
+	termio.RegisterCallback(EventTypeCommandEnd, func(event *Event) {
+		ends = append(ends, event.Data.(CommandEndEvent))
+	})
+
+	require.NoError(t, termio.ProcessOutput([]byte(
+		"\x1b]133;A;aid=42;cl=line\x07$ \x1b]133;B\x07ls -la\r\n\x1b]133;C\x07",
+	)))
+	require.Len(t, prompts, 1)
+	assert.Equal(t, "$ ", prompts[0].Content)
+	assert.Equal(t, "primary", prompts[0].Type)
+	assert.Equal(t, "42", prompts[0].Aid)
+	assert.Equal(t, "line", prompts[0].Click)
+	assert.Equal(t, 2, prompts[0].Position.X)
+
+	require.Len(t, starts, 1)
+	assert.Equal(t, "ls -la", starts[0].Content)
+	assert.Equal(t, "42", starts[0].Aid)
+	assert.Equal(t, 1, starts[0].Position.Y)
+
+	rowType := func(y uint16) page.SemanticPromptType {
+		pin := termio.terminal.Screen.Pages.Pin(point.Point{
+			Tag:        point.TagActive,
+			Coordinate: coordinate.Point[size.CellCountInt]{Y: size.CellCountInt(y)},
+		})
+		require.NotNil(t, pin)
+		return pin.RowAndCell().Row.SemanticPrompt
+	}
+	assert.Equal(t, page.SemanticPromptTypeInput, rowType(0))
+	assert.Equal(t, page.SemanticPromptTypeOutput, rowType(1))
+	assert.False(t, termio.terminal.CursorIsAtPrompt())
+
+	// Enough output to scroll the prompt off the screen.
+	for range 10 {
+		require.NoError(t, termio.ProcessOutput([]byte("output\r\n")))
+	}
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b]133;D;2\x07")))
+	require.Len(t, ends, 1)
+	assert.Equal(t, 2, ends[0].ExitCode)
+	assert.Equal(t, "42", ends[0].Aid)
+	assert.GreaterOrEqual(t, ends[0].Duration, int64(0))
+	assert.GreaterOrEqual(t, ends[0].Timestamp, starts[0].Timestamp)
+
+	// D without C, e.g. an empty command line, doesn't end a command.
+	require.NoError(t, termio.ProcessOutput([]byte(
+		"\x1b]133;A\x07> \x1b]133;B\x07\r\n\x1b]133;D\x07",
+	)))
+	assert.Len(t, ends, 1)
+
+	// A command spanning a continuation prompt is read as a whole, the
+	// exit code is unknown when not reported.
+	require.NoError(t, termio.ProcessOutput([]byte(
+		"\x1b]133;A\x07$ \x1b]133;B\x07echo \\\r\n" +
+			"\x1b]133;A;k=c\x07> \x1b]133;B\x07done\r\n\x1b]133;C\x07\x1b]133;D\x1b\\",
+	)))
+	require.Len(t, prompts, 4)
+	assert.Equal(t, "continuation", prompts[3].Type)
+	assert.Equal(t, "> ", prompts[3].Content)
+	require.Len(t, starts, 2)
+	assert.Equal(t, "echo \\\n> done", starts[1].Content)
+	require.Len(t, ends, 2)
+	assert.Equal(t, -1, ends[1].ExitCode)
+}
+
+func TestTerminalIOHyperlink(t *testing.T) {
+	termio := NewTerminalIO(Options{
+		Rows:   5,
+		Cols:   20,
+		Logger: logger.New(logger.Options{Buffer: io.Discard}),
+	})
+
+	at := func(x, y size.CellCountInt) point.Point {
+		return point.Point{
+			Tag:        point.TagActive,
+			Coordinate: coordinate.Point[size.CellCountInt]{X: x, Y: y},
+		}
+	}
+
+	// ls --hyperlink style output, terminated with both BEL and ST.
+	require.NoError(t, termio.ProcessOutput([]byte(
+		"\x1b]8;;file:///tmp/a.txt\x07a.txt\x1b]8;;\x07  " +
+			"\x1b]8;id=b;file:///tmp/b.txt\x1b\\b.txt\x1b]8;;\x1b\\",
+	)))
+	assert.Equal(t, "a.txt  b.txt", termio.DumpString())
+
+	link := termio.HyperlinkAt(at(2, 0))
+	require.NotNil(t, link)
+	assert.Equal(t, "file:///tmp/a.txt", link.URI)
+	assert.Equal(t, at(0, 0), link.Start)
+	assert.Equal(t, at(4, 0), link.End)
+
+	assert.Nil(t, termio.HyperlinkAt(at(5, 0)))
+
+	link = termio.HyperlinkAt(at(7, 0))
+	require.NotNil(t, link)
+	assert.Equal(t, "file:///tmp/b.txt", link.URI)
+	assert.Equal(t, "b", link.ID)
+	assert.Equal(t, at(7, 0), link.Start)
+	assert.Equal(t, at(11, 0), link.End)
+
+	// Text printed after the link is closed isn't part of it.
+	require.NoError(t, termio.ProcessOutput([]byte("\r\nplain")))
+	assert.Nil(t, termio.HyperlinkAt(at(0, 1)))
+}
+
+func TestTerminalIOClipboard(t *testing.T) {
+	clipboard := NewMemoryClipboard()
+	responses := &bytes.Buffer{}
+	termio := NewTerminalIO(Options{
+		Rows:           24,
+		Cols:           80,
+		Logger:         logger.New(logger.Options{Buffer: io.Discard}),
+		ResponseWriter: responses,
+		Clipboard:      clipboard,
+	})
+
+	var events []ClipboardEvent
+	termio.RegisterCallback(EventTypeClipboard, func(event *Event) {
+		events = append(events, event.Data.(ClipboardEvent))
+	})
+
+	// Set, the selection target defaults to the clipboard.
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b]52;;aGVsbG8=\x07")))
+	data, err := clipboard.GetClipboard(ClipboardStandard)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", data)
+
+	// Unpadded payloads and the primary selection.
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b]52;p;d29ybGQ\x1b\\")))
+	data, err = clipboard.GetClipboard(ClipboardPrimary)
+	require.NoError(t, err)
+	assert.Equal(t, "world", data)
+
+	// Invalid payloads are ignored.
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b]52;c;!!!\x07")))
+	data, err = clipboard.GetClipboard(ClipboardStandard)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", data)
+
+	// Reads are answered with the same terminator as the request.
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b]52;c;?\x1b\\")))
+	assert.Equal(t, "\x1b]52;c;aGVsbG8=\x1b\\", responses.String())
+	responses.Reset()
+
+	// Nothing was copied to the selection buffer, so there is no reply.
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b]52;s;?\x07")))
+	assert.Empty(t, responses.String())
+
+	assert.Equal(t, []ClipboardEvent{
+		{Kind: ClipboardStandard, Operation: "set", Data: "hello"},
+		{Kind: ClipboardPrimary, Operation: "set", Data: "world"},
+		{Kind: ClipboardStandard, Operation: "get", Data: "hello"},
+	}, events)
+}
+
+func TestTerminalIODynamicColors(t *testing.T) {
+	responses := &bytes.Buffer{}
+	termio := NewTerminalIO(Options{
+		Rows:            24,
+		Cols:            80,
+		Logger:          logger.New(logger.Options{Buffer: io.Discard}),
+		ResponseWriter:  responses,
+		ForegroundColor: &color.RGB{R: 0xFF, G: 0xFF, B: 0xFF},
+		BackgroundColor: &color.RGB{R: 0x28, G: 0x2C, B: 0x34},
+	})
+
+	var events []ColorEvent
+	termio.RegisterCallback(EventTypeColor, func(event *Event) {
+		events = append(events, event.Data.(ColorEvent))
+	})
+
+	query := func(seq string) string {
+		responses.Reset()
+		require.NoError(t, termio.ProcessOutput([]byte(seq)))
+		return responses.String()
+	}
+
+	// Query the configured defaults, the cursor follows the foreground.
+	// Every parameter of OSC 10 moves on to the next dynamic color.
+	assert.Equal(t, "\x1b]11;rgb:2828/2c2c/3434\x1b\\", query("\x1b]11;?\x1b\\"))
+	assert.Equal(t,
+		"\x1b]10;rgb:ffff/ffff/ffff\x07\x1b]11;rgb:2828/2c2c/3434\x07",
+		query("\x1b]10;?;?\x07"),
+	)
+	assert.Equal(t, "\x1b]12;rgb:ffff/ffff/ffff\x07", query("\x1b]12;?\x07"))
+
+	// Palette entries can be changed, queried and reset.
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b]4;1;rgb:12/34/56;2;#abcdef\x07")))
+	assert.Equal(t, color.RGB{R: 0x12, G: 0x34, B: 0x56}, termio.terminal.PaletteColor(1))
+	assert.Equal(t,
+		"\x1b]4;1;rgb:1212/3434/5656\x07\x1b]4;3;rgb:f0f0/c6c6/7474\x07",
+		query("\x1b]4;1;?;3;?\x07"),
+	)
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b]104;1\x07")))
+	assert.Equal(t, color.DefaultPalette[1], termio.terminal.PaletteColor(1))
+	assert.Equal(t, color.RGB{R: 0xAB, G: 0xCD, B: 0xEF}, termio.terminal.PaletteColor(2))
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b]104\x07")))
+	assert.Equal(t, color.Palette(color.DefaultPalette), termio.terminal.Palette())
+
+	// Dynamic colors can be changed and reset to the configured defaults.
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b]11;#000000\x07\x1b]12;#ff0000\x07")))
+	assert.Equal(t, "\x1b]11;rgb:0000/0000/0000\x07", query("\x1b]11;?\x07"))
+	assert.Equal(t, "\x1b]12;rgb:ffff/0000/0000\x07", query("\x1b]12;?\x07"))
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b]111\x07\x1b]112\x07")))
+	assert.Equal(t, "\x1b]11;rgb:2828/2c2c/3434\x07", query("\x1b]11;?\x07"))
+	assert.Equal(t, "\x1b]12;rgb:ffff/ffff/ffff\x07", query("\x1b]12;?\x07"))
+
+	assert.Equal(t, []ColorEvent{
+		{Target: "palette", Index: 1, RGB: color.RGB{R: 0x12, G: 0x34, B: 0x56}},
+		{Target: "palette", Index: 2, RGB: color.RGB{R: 0xAB, G: 0xCD, B: 0xEF}},
+		{Target: "palette", Index: 1, RGB: color.DefaultPalette[1]},
+		{Target: "palette", Index: -1},
+		{Target: "background", Index: -1, RGB: color.RGB{}},
+		{Target: "cursor", Index: -1, RGB: color.RGB{R: 0xFF}},
+		{Target: "background", Index: -1, RGB: color.RGB{R: 0x28, G: 0x2C, B: 0x34}},
+		{Target: "cursor", Index: -1, RGB: color.RGB{R: 0xFF, G: 0xFF, B: 0xFF}},
+	}, events)
+}
+
+func TestTerminalIOResponses(t *testing.T) {
+	clipboard := NewMemoryClipboard()
+	require.NoError(t, clipboard.SetClipboard(ClipboardStandard, "copied"))
+	termio := NewTerminalIO(Options{
+		Rows:      24,
+		Cols:      80,
+		Logger:    logger.New(logger.Options{Buffer: io.Discard}),
+		Clipboard: clipboard,
+	})
+
+	// Processing doesn't wait for the replies to be read.
+	for range 3 {
+		require.NoError(t, termio.ProcessOutput([]byte(
+			"\x1b]11;?\x07\x1b]52;c;?\x07\x1b]4;1;?\x07",
+		)))
+	}
+
+	// Replies are delivered in the order of the queries.
+	for range 3 {
+		assert.Equal(t, "\x1b]11;rgb:1d1d/1f1f/2121\x07", string(<-termio.Responses()))
+		assert.Equal(t, "\x1b]52;c;Y29waWVk\x07", string(<-termio.Responses()))
+		assert.Equal(t, "\x1b]4;1;rgb:cccc/6666/6666\x07", string(<-termio.Responses()))
+	}
+
+	require.NoError(t, termio.Close())
+	_, ok := <-termio.Responses()
+	assert.False(t, ok)
+
+	// With a response writer, the channel isn't used.
+	termio = NewTerminalIO(Options{
+		Rows:           24,
+		Cols:           80,
+		Logger:         logger.New(logger.Options{Buffer: io.Discard}),
+		ResponseWriter: io.Discard,
+	})
+	assert.Nil(t, termio.Responses())
+}
+
+func TestTerminalIOResponsesDropped(t *testing.T) {
+	termio := NewTerminalIO(Options{
+		Rows:   24,
+		Cols:   80,
+		Logger: logger.New(logger.Options{Buffer: io.Discard}),
+	})
+
+	// The oldest replies are dropped when too many are pending.
+	for i := range maxPendingResponses + 2 {
+		require.NoError(t, termio.ProcessOutput(fmt.Appendf(nil, "\x1b[%dH\x1b[6n", i%24+1)))
+	}
+	assert.Equal(t, 2, termio.DroppedResponses())
+	assert.Equal(t, "\x1b[3;1R", string(<-termio.Responses()))
+
+	require.NoError(t, termio.Close())
+	for range termio.Responses() {
+	}
+
+	// Closing before reading closes the channel too.
+	termio = NewTerminalIO(Options{
+		Rows:   24,
+		Cols:   80,
+		Logger: logger.New(logger.Options{Buffer: io.Discard}),
+	})
+	require.NoError(t, termio.Close())
+	_, ok := <-termio.Responses()
+	assert.False(t, ok)
+}
+
+func TestTerminalIODeviceReports(t *testing.T) {
+	responses := &bytes.Buffer{}
+	termio := NewTerminalIO(Options{
+		Rows:           24,
+		Cols:           80,
+		Logger:         logger.New(logger.Options{Buffer: io.Discard}),
+		ResponseWriter: responses,
+	})
+
+	query := func(seq string) string {
+		responses.Reset()
+		require.NoError(t, termio.ProcessOutput([]byte(seq)))
+		return responses.String()
+	}
+
+	// Device attributes with the default identity.
+	assert.Equal(t, "\x1b[?62;22c", query("\x1b[c"))
+	assert.Equal(t, "\x1b[?62;22c", query("\x1b[0c"))
+	assert.Equal(t, "\x1b[>1;10;0c", query("\x1b[>c"))
+	assert.Equal(t, "\x1bP!|00000000\x1b\\", query("\x1b[=0c"))
+	assert.Empty(t, query("\x1b[1c"))
+
+	// Operating status and cursor position, the position is 1-based.
+	assert.Equal(t, "\x1b[0n", query("\x1b[5n"))
+	assert.Equal(t, "\x1b[1;1R", query("\x1b[6n"))
+	assert.Equal(t, "\x1b[3;6R", query("\x1b[3;6H\x1b[6n"))
+	assert.Equal(t, "\x1b[?3;6;1R", query("\x1b[?6n"))
+	assert.Empty(t, query("\x1b[7n"))
+
+	// The identity is configurable.
+	termio = NewTerminalIO(Options{
+		Rows:           24,
+		Cols:           80,
+		Logger:         logger.New(logger.Options{Buffer: io.Discard}),
+		ResponseWriter: responses,
+		DeviceIdentity: &terminal.DeviceIdentity{
+			Primary:   []uint16{64, 1, 4, 22},
+			Type:      41,
+			Version:   380,
+			Cartridge: 0,
+			UnitID:    0x7E7E0001,
+		},
+	})
+	assert.Equal(t, "\x1b[?64;1;4;22c", query("\x1b[c"))
+	assert.Equal(t, "\x1b[>41;380;0c", query("\x1b[>0c"))
+	assert.Equal(t, "\x1bP!|7E7E0001\x1b\\", query("\x1b[=c"))
+}
+
+func TestTerminalIOIndexedColors(t *testing.T) {
+	termio := NewTerminalIO(Options{
+		Rows:           3,
+		Cols:           20,
+		Logger:         logger.New(logger.Options{Buffer: io.Discard}),
+		ResponseWriter: io.Discard,
+	})
+
+	require.NoError(t, termio.ProcessOutput([]byte(
+		"\x1b[31mred\x1b[0m \x1b[92;44mbright\x1b[m \x1b[38;5;200;48;5;17mx\x1b[m",
+	)))
+	assert.Equal(t,
+		"\x1b[31mred\x1b[0m \x1b[92;44mbright\x1b[0m \x1b[38;5;200;48;5;17mx\x1b[0m",
+		termio.DumpStringWithFormatting(),
+	)
+
+	// The cursor keeps the palette colors until they are reset.
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b[97;101;58;5;9m")))
+	cursorStyle := termio.terminal.Screen.Cursor.Style
+	assert.Equal(t, style.Color{Type: style.ColorTypePalette, Palette: 15}, cursorStyle.ForegroundColor)
+	assert.Equal(t, style.Color{Type: style.ColorTypePalette, Palette: 9}, cursorStyle.BackgroundColor)
+	assert.Equal(t, style.Color{Type: style.ColorTypePalette, Palette: 9}, cursorStyle.UnderlineColor)
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b[39;49;59m")))
+	assert.True(t, termio.terminal.Screen.Cursor.Style.IsDefault())
+}
+
+func TestTerminalIOScrollingRegion(t *testing.T) {
+	responses := &bytes.Buffer{}
+	termio := NewTerminalIO(Options{
+		Rows:           5,
+		Cols:           10,
+		Logger:         logger.New(logger.Options{Buffer: io.Discard}),
+		ResponseWriter: responses,
+	})
+
+	require.NoError(t, termio.ProcessOutput([]byte("1\r\n2\r\n3\r\n4\r\n5")))
+
+	// Lines scroll within the top and bottom margins only.
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b[2;4r\x1b[4;1Hx\r\ny")))
+	assert.Equal(t, "1\n3\nx\ny\n5", termio.DumpString())
+
+	// Both modes are set, the cursor position is relative to the region.
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b[?6;69h\x1b[3;4s\x1b[2;2H")))
+	assert.True(t, termio.terminal.Modes.Get(core.ModeOrigin))
+	assert.True(t, termio.terminal.Modes.Get(core.ModeEnableLeftAndRightMargin))
+	responses.Reset()
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b[6n")))
+	assert.Equal(t, "\x1b[2;2R", responses.String())
+	assert.Equal(t, size.CellCountInt(3), termio.terminal.Screen.Cursor.X)
+	assert.Equal(t, size.CellCountInt(2), termio.terminal.Screen.Cursor.Y)
+
+	// Resetting DECLRMM drops the left and right margins, CSI r without
+	// parameters resets the top and bottom margins.
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b[?6;69l\x1b[r\x1b[5;1H\n")))
+	assert.Equal(t, "3\nx\ny\n5", termio.DumpString())
+}
+
+func TestTerminalIOAlternateScreen(t *testing.T) {
+	termio := NewTerminalIO(Options{
+		Rows:   3,
+		Cols:   10,
+		Logger: logger.New(logger.Options{Buffer: io.Discard}),
+	})
+
+	var events []ScreenEvent
+	termio.RegisterCallback(EventTypeScreen, func(event *Event) {
+		events = append(events, event.Data.(ScreenEvent))
+	})
+
+	require.NoError(t, termio.ProcessOutput([]byte("$ vim\x1b[?1049h\x1b[Hediting")))
+	assert.Equal(t, terminal.ScreenTypeAlternate, termio.ActiveScreen())
+	assert.Equal(t, "editing", termio.DumpString())
+
+	// Setting the mode again doesn't switch screens.
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b[?1049h")))
+
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b[?1049l")))
+	assert.Equal(t, terminal.ScreenTypePrimary, termio.ActiveScreen())
+	assert.Equal(t, "$ vim", termio.DumpString())
+	assert.Equal(t, size.CellCountInt(5), termio.terminal.Screen.Cursor.X)
+
+	assert.Equal(t, []ScreenEvent{
+		{Screen: terminal.ScreenTypeAlternate, Mode: 1049},
+		{Screen: terminal.ScreenTypePrimary, Mode: 1049},
+	}, events)
+
+	// A full reset goes back to a blank primary screen.
+	events = nil
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b[?1049h\x1bc")))
+	assert.Equal(t, terminal.ScreenTypePrimary, termio.ActiveScreen())
+	assert.Equal(t, "", termio.DumpString())
+	assert.Equal(t, []ScreenEvent{
+		{Screen: terminal.ScreenTypeAlternate, Mode: 1049},
+		{Screen: terminal.ScreenTypePrimary},
+	}, events)
+
+	// Resetting on the primary screen doesn't switch screens.
+	events = nil
+	require.NoError(t, termio.ProcessOutput([]byte("\x1bc")))
+	assert.Empty(t, events)
+}
+
+func TestTerminalIOSaveCursor(t *testing.T) {
+	termio := NewTerminalIO(Options{
+		Rows:   3,
+		Cols:   10,
+		Logger: logger.New(logger.Options{Buffer: io.Discard}),
+	})
+
+	// A prompt redraw: save the cursor, draw the right prompt, restore.
+	require.NoError(t, termio.ProcessOutput([]byte("$ \x1b7\x1b[1;6H\x1b[1m10:00\x1b8ls")))
+	assert.Equal(t, "$ ls 10:00", termio.DumpString())
+	assert.False(t, termio.terminal.Screen.Cursor.Style.Bold)
+
+	// CSI s and CSI u without DECLRMM.
+	require.NoError(t, termio.ProcessOutput([]byte("\r\n\x1b[s\x1b[3;5Hx\x1b[uy")))
+	assert.Equal(t, "$ ls 10:00\ny\n    x", termio.DumpString())
+
+	// Line drawing with the DEC special graphics, the character set is
+	// restored with the cursor.
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b[2;1H\x1b7\x1b(0lqk\x1b8\x1b[3;1Hlqk")))
+	assert.Equal(t, "$ ls 10:00\n┌─┐\nlqk x", termio.DumpString())
+
+	// SO and SI switch between G1 and G0.
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b)0\x1b[3;1H\x0ex\x0fx")))
+	assert.Equal(t, "$ ls 10:00\n┌─┐\n│xk x", termio.DumpString())
+}
+
+func TestTerminalIOScrollAndErase(t *testing.T) {
+	termio := NewTerminalIO(Options{
+		Rows:   4,
+		Cols:   10,
+		Logger: logger.New(logger.Options{Buffer: io.Discard}),
+	})
+	require.NoError(t, termio.ProcessOutput([]byte("1\r\n2\r\n3\r\n4")))
+
+	// A progress display: scroll the region, redraw the bar in place.
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b[1;3r\x1b[S\x1b[3;1H[###  ]\x1b[3;2H\x1b[2X")))
+	assert.Equal(t, "2\n3\n[  #  ]\n4", termio.DumpString())
+
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b[2T")))
+	assert.Equal(t, "\n\n2\n4", termio.DumpString())
+
+	// Inserting and deleting characters and lines with a count.
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b[r\x1b[1;1Habcd\x1b[1;1H\x1b[2@")))
+	assert.Equal(t, "  abcd\n\n2\n4", termio.DumpString())
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b[4P")))
+	assert.Equal(t, "cd\n\n2\n4", termio.DumpString())
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b[2M")))
+	assert.Equal(t, "2\n4", termio.DumpString())
+}
+
+func TestTerminalIORepeat(t *testing.T) {
+	termio := NewTerminalIO(Options{
+		Rows:   3,
+		Cols:   10,
+		Logger: logger.New(logger.Options{Buffer: io.Discard}),
+	})
+	var chars int
+	termio.RegisterCallback(EventTypeCharacter, func(event *Event) {
+		chars++
+	})
+
+	// Nothing was printed yet.
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b[3b")))
+	assert.Equal(t, "", termio.DumpString())
+
+	// A horizontal rule as drawn by ncurses.
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b(0q\x1b[9b\x1b(B\r\nab\x1b[b")))
+	assert.Equal(t, "──────────\nabb", termio.DumpString())
+	assert.Equal(t, 13, chars)
+
+	// Repeated characters wrap like printed ones, wide characters that
+	// don't fit at the end of the line wrap as a whole.
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b[0b\x1b[7b\r\nx橋\x1b[4b")))
+	assert.Equal(t, "b\nx橋橋橋橋\n橋", termio.DumpString())
+}
+
+func TestTerminalIOTabstops(t *testing.T) {
+	responses := &bytes.Buffer{}
+	termio := NewTerminalIO(Options{
+		Rows:           2,
+		Cols:           30,
+		Logger:         logger.New(logger.Options{Buffer: io.Discard}),
+		ResponseWriter: responses,
+	})
+
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b[2$w")))
+	assert.Equal(t, "\x1bP2$u9/17/25\x1b\\", responses.String())
+
+	// Columns of a table: clear all, set new stops with HTS.
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b[3g\x1b[1;5H\x1bH\x1b[1;13H\x1bH\r")))
+	require.NoError(t, termio.ProcessOutput([]byte("a\tb\tc\x1b[Zx\x1b[2Zy\x1b[2Iz")))
+	assert.Equal(t, "a   y       x                z", termio.DumpString())
+	responses.Reset()
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b[2$w")))
+	assert.Equal(t, "\x1bP2$u5/13\x1b\\", responses.String())
+
+	// Clear the stop at the cursor, then reset to every 8 columns.
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b[1;5H\x1b[g")))
+	responses.Reset()
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b[2$w")))
+	assert.Equal(t, "\x1bP2$u13\x1b\\", responses.String())
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b[?5W")))
+	responses.Reset()
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b[2$w")))
+	assert.Equal(t, "\x1bP2$u9/17/25\x1b\\", responses.String())
+}
+
+func TestTerminalIORequestMode(t *testing.T) {
+	responses := &bytes.Buffer{}
+	termio := NewTerminalIO(Options{
+		Rows:           3,
+		Cols:           10,
+		Logger:         logger.New(logger.Options{Buffer: io.Discard}),
+		ResponseWriter: responses,
+	})
+
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b[?7$p\x1b[?6$p\x1b[4$p")))
+	assert.Equal(t, "\x1b[?7;1$y\x1b[?6;2$y\x1b[4;2$y", responses.String())
+
+	// DECANM can't be changed, synchronized output isn't supported.
+	responses.Reset()
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b[?2l\x1b[?2$p\x1b[?2026$p")))
+	assert.Equal(t, "\x1b[?2;3$y\x1b[?2026;0$y", responses.String())
+}
+
+func TestTerminalIOSaveMode(t *testing.T) {
+	termio := NewTerminalIO(Options{
+		Rows:   3,
+		Cols:   10,
+		Logger: logger.New(logger.Options{Buffer: io.Discard}),
+	})
+
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b[?7;1049s\x1b[?7l\x1b[?1049h")))
+	assert.False(t, termio.terminal.Modes.Get(core.ModeWraparound))
+	assert.Equal(t, terminal.ScreenTypeAlternate, termio.ActiveScreen())
+
+	// Restoring a mode applies it, e.g. switches back to the primary
+	// screen.
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b[?7;1049r")))
+	assert.True(t, termio.terminal.Modes.Get(core.ModeWraparound))
+	assert.Equal(t, terminal.ScreenTypePrimary, termio.ActiveScreen())
+
+	// Modes that weren't saved are left as is.
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b[?6h\x1b[?6r")))
+	assert.True(t, termio.terminal.Modes.Get(core.ModeOrigin))
+}
+
+func TestTerminalIOSoftReset(t *testing.T) {
+	termio := NewTerminalIO(Options{
+		Rows:   3,
+		Cols:   10,
+		Logger: logger.New(logger.Options{Buffer: io.Discard}),
+	})
+
+	require.NoError(t, termio.ProcessOutput([]byte("keep\r\n\x1b[4h\x1b[1m\x1b(0\x1b[2;3r")))
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b[!p")))
+	assert.Equal(t, "keep", termio.DumpString())
+	assert.False(t, termio.terminal.Modes.Get(core.ModeInsert))
+	assert.Equal(t, style.Style{}, termio.terminal.Screen.Cursor.Style)
+
+	// The character sets are reset, q is printed as is.
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b[3;1Hq")))
+	assert.Equal(t, "keep\n\nq", termio.DumpString())
+}
+
+func TestTerminalIOCursorStyle(t *testing.T) {
+	termio := NewTerminalIO(Options{
+		Rows:   3,
+		Cols:   10,
+		Logger: logger.New(logger.Options{Buffer: io.Discard}),
+	})
+	var events []CursorStyleEvent
+	termio.RegisterCallback(EventTypeCursorStyle, func(event *Event) {
+		events = append(events, event.Data.(CursorStyleEvent))
+	})
+
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b[6 q")))
+	assert.Equal(t, screen.CursorShapeBar, termio.CursorShape())
+	assert.False(t, termio.CursorBlinking())
+
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b[?25l")))
+	assert.False(t, termio.CursorVisible())
+
+	// Invalid styles are ignored.
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b[7 q\x1b[?25h\x1b[ q")))
+	assert.True(t, termio.CursorVisible())
+	assert.Equal(t, screen.CursorShapeBlock, termio.CursorShape())
+	assert.True(t, termio.CursorBlinking())
+
+	assert.Equal(t, []CursorStyleEvent{
+		{Shape: screen.CursorShapeBar, Blinking: false, Visible: true},
+		{Shape: screen.CursorShapeBar, Blinking: false, Visible: false},
+		{Shape: screen.CursorShapeBar, Blinking: false, Visible: true},
+		{Shape: screen.CursorShapeBlock, Blinking: true, Visible: true},
+	}, events)
+
+	// The resets show the cursor again, RIS also resets the shape.
+	events = nil
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b[?25l\x1b[!p")))
+	assert.True(t, termio.CursorVisible())
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b[4 q\x1bc")))
+	assert.Equal(t, screen.CursorShapeBlock, termio.CursorShape())
+
+	// Nothing is reported if the reset doesn't change the cursor.
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b[!p\x1bc")))
+	assert.Equal(t, []CursorStyleEvent{
+		{Shape: screen.CursorShapeBlock, Blinking: true, Visible: false},
+		{Shape: screen.CursorShapeBlock, Blinking: true, Visible: true},
+		{Shape: screen.CursorShapeUnderline, Blinking: false, Visible: true},
+		{Shape: screen.CursorShapeBlock, Blinking: true, Visible: true},
+	}, events)
+}
+
+func TestTerminalIOEncodeKey(t *testing.T) {
+	termio := NewTerminalIO(Options{
+		Rows:   3,
+		Cols:   10,
+		Logger: logger.New(logger.Options{Buffer: io.Discard}),
+	})
+	assert.Equal(t, "\x1b[A", string(termio.EncodeKey(input.KeyEvent{Key: input.KeyUp})))
+	assert.Equal(t, "1", string(termio.EncodeKey(input.KeyEvent{Key: input.KeyKp1})))
+
+	// The program switches to the application modes, e.g. vim with DECCKM
+	// and DECKPAM, and asks for modifyOtherKeys.
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b[?1h\x1b=\x1b[>4;2m")))
+	assert.Equal(t, "\x1bOA", string(termio.EncodeKey(input.KeyEvent{Key: input.KeyUp})))
+	assert.Equal(t, "\x1bOq", string(termio.EncodeKey(input.KeyEvent{Key: input.KeyKp1})))
+	assert.Equal(t, "\x1b[27;5;105~", string(termio.EncodeKey(input.KeyEvent{Text: "i", Mods: input.ModCtrl})))
+
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b[?1l\x1b>\x1b[>4m")))
+	assert.Equal(t, "\x1b[A", string(termio.EncodeKey(input.KeyEvent{Key: input.KeyUp})))
+	assert.Equal(t, "1", string(termio.EncodeKey(input.KeyEvent{Key: input.KeyKp1})))
+	assert.Equal(t, "\t", string(termio.EncodeKey(input.KeyEvent{Text: "i", Mods: input.ModCtrl})))
+}
+
+func TestTerminalIOKittyKeyboard(t *testing.T) {
+	responses := &bytes.Buffer{}
+	termio := NewTerminalIO(Options{
+		Rows:           3,
+		Cols:           10,
+		Logger:         logger.New(logger.Options{Buffer: io.Discard}),
+		ResponseWriter: responses,
+	})
+	ctrlA := input.KeyEvent{Text: "a", Mods: input.ModCtrl}
+
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b[?u")))
+	assert.Equal(t, "\x1b[?0u", responses.String())
+	assert.Equal(t, "\x01", string(termio.EncodeKey(ctrlA)))
+
+	responses.Reset()
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b[>1u\x1b[?u")))
+	assert.Equal(t, "\x1b[?1u", responses.String())
+	assert.Equal(t, "\x1b[97;5u", string(termio.EncodeKey(ctrlA)))
+
+	// The alternate screen has its own stack.
+	responses.Reset()
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b[?1049h\x1b[?u\x1b[>3u\x1b[=8;2u\x1b[?u")))
+	assert.Equal(t, "\x1b[?0u\x1b[?11u", responses.String())
+	assert.Equal(t, "\x1b[97u", string(termio.EncodeKey(input.KeyEvent{Text: "a"})))
+
+	responses.Reset()
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b[?1049l\x1b[?u\x1b[<u\x1b[?u")))
+	assert.Equal(t, "\x1b[?1u\x1b[?0u", responses.String())
+	assert.Equal(t, "\x01", string(termio.EncodeKey(ctrlA)))
+}
+
+func TestTerminalIOEncodeMouse(t *testing.T) {
+	termio := NewTerminalIO(Options{
+		Rows:   3,
+		Cols:   10,
+		Logger: logger.New(logger.Options{Buffer: io.Discard}),
+	})
+	click := input.MouseEvent{Action: input.MouseActionPress, Button: input.MouseButtonLeft, X: 2, Y: 1}
+	motion := input.MouseEvent{Action: input.MouseActionMotion, X: 3, Y: 1}
+	assert.Nil(t, termio.EncodeMouse(click))
+
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b[?1000h\x1b[?1006h")))
+	assert.Equal(t, "\x1b[<0;3;2M", string(termio.EncodeMouse(click)))
+	assert.Nil(t, termio.EncodeMouse(motion))
+
+	// Setting another tracking mode replaces the previous one.
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b[?1003h")))
+	assert.False(t, termio.terminal.Modes.Get(core.ModeMouseNormal))
+	assert.Equal(t, "\x1b[<35;4;2M", string(termio.EncodeMouse(motion)))
+
+	// Resetting any tracking mode stops the tracking, resetting the format
+	// goes back to the default one.
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b[?1006l\x1b[?1000l")))
+	assert.Nil(t, termio.EncodeMouse(click))
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b[?1000h")))
+	assert.Equal(t, "\x1b[M #\"", string(termio.EncodeMouse(click)))
+}
+
+func TestTerminalIOEncodePaste(t *testing.T) {
+	termio := NewTerminalIO(Options{
+		Rows:   3,
+		Cols:   10,
+		Logger: logger.New(logger.Options{Buffer: io.Discard}),
+	})
+	assert.Equal(t, "a\rb", string(termio.EncodePaste("a\nb")))
+
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b[?2004h")))
+	assert.Equal(t, "\x1b[200~a\nb\x1b[201~", string(termio.EncodePaste("a\nb\x1b[201~")))
+
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b[?2004l")))
+	assert.Equal(t, "a\rb", string(termio.EncodePaste("a\nb")))
+}
+
+func TestTerminalIOSetFocused(t *testing.T) {
+	responses := &bytes.Buffer{}
+	termio := NewTerminalIO(Options{
+		Rows:           3,
+		Cols:           10,
+		Logger:         logger.New(logger.Options{Buffer: io.Discard}),
+		ResponseWriter: responses,
+	})
+
+	// Nothing is reported unless the application asked for it.
+	termio.SetFocused(false)
+	termio.SetFocused(true)
+	assert.Empty(t, responses.String())
+
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b[?1004h")))
+	termio.SetFocused(false)
+	termio.SetFocused(false)
+	termio.SetFocused(true)
+	assert.Equal(t, "\x1b[O\x1b[I", responses.String())
+
+	responses.Reset()
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b[?1004l")))
+	termio.SetFocused(false)
+	assert.Empty(t, responses.String())
+}
+
+func TestTerminalIOAlternateScroll(t *testing.T) {
+	termio := NewTerminalIO(Options{
+		Rows:   3,
+		Cols:   10,
+		Logger: logger.New(logger.Options{Buffer: io.Discard}),
+	})
+	wheel := input.MouseEvent{Action: input.MouseActionPress, Button: input.MouseButtonWheelUp}
+
+	// The wheel is only sent as arrows on the alternate screen.
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b[?1007h")))
+	assert.Nil(t, termio.EncodeMouse(wheel))
+
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b[?1049h")))
+	assert.Equal(t, "\x1b[A", string(termio.EncodeMouse(wheel)))
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b[?1h")))
+	assert.Equal(t, "\x1bOA", string(termio.EncodeMouse(wheel)))
+
+	// Mouse tracking takes precedence.
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b[?1000h\x1b[?1006h")))
+	assert.Equal(t, "\x1b[<64;1;1M", string(termio.EncodeMouse(wheel)))
+
+	require.NoError(t, termio.ProcessOutput([]byte("\x1b[?1000l\x1b[?1007l")))
+	assert.Nil(t, termio.EncodeMouse(wheel))
+}